@@ -0,0 +1,60 @@
+package sse
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrReconnectAdvised error is the cause of reconnections requested by the
+	// server, see WithReconnectAdvisory. It is not reported on Errors.
+	ErrReconnectAdvised = errors.New("eventsource: reconnection advised by the server")
+)
+
+// WithReconnectAdvisory lets the server ask the event source to reconnect, e.g.
+// before a deployment, by sending an event with the given name, such as
+// "system/reconnect". Such events are not delivered: the event source closes
+// the connection, waits for the number of milliseconds of the event data, or
+// the retry time if the data is empty, then reconnects.
+// The advised delay is clamped by WithMinRetry and WithMaxRetry.
+func WithReconnectAdvisory(name string) EventSourceOption {
+	return func(es *EventSource) {
+		es.advisory = name
+	}
+}
+
+func (es *EventSource) isAdvisory(ev *MessageEvent) bool {
+	return es.advisory != "" && ev.Name == es.advisory
+}
+
+// advisedReconnect closes the current connection and reconnects after the delay
+// advised by ev.
+func (es *EventSource) advisedReconnect(ev *MessageEvent) {
+	delay := es.Retry()
+	if ms, err := strconv.Atoi(strings.TrimSpace(ev.Data)); err == nil && ms >= 0 {
+		delay = es.clampRetry(time.Duration(ms) * time.Millisecond)
+	}
+	es.logf("eventsource: server advised reconnecting to %s in %v", es.url, delay)
+	es.resp.Body.Close()
+	es.stateMutex.Lock()
+	es.disconnectCount++
+	es.stateMutex.Unlock()
+	es.setReadyState(Status{Connecting, nil})
+
+	if !es.sleep(delay) || !es.waitResume() {
+		return
+	}
+	es.reconnectInfo = &ReconnectInfo{Cause: ErrReconnectAdvised, Delay: delay}
+	err := es.connectOnce()
+	switch {
+	case err == nil:
+	case es.mustReconnect(err):
+		es.notifyError(err)
+		es.reconnect(err)
+	default:
+		es.notifyError(err)
+		es.Close(err)
+	}
+}
@@ -0,0 +1,45 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourceWithReconnectAdvisory(t *testing.T) {
+	var requests int32
+	times := make(chan time.Time, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		times <- time.Now()
+		rw.Header().Set("Content-Type", allowedContentType)
+		if atomic.AddInt32(&requests, 1) == 1 {
+			rw.Write([]byte("id: 1\ndata: first\n\nevent: system/reconnect\ndata: 100\n\n"))
+		} else {
+			assert.Equal(t, "1", req.Header.Get("Last-Event-ID"))
+			rw.Write([]byte("data: second\n\n"))
+		}
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL, WithReconnectAdvisory("system/reconnect"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+
+	assert.Equal(t, "first", (<-es.MessageEvents()).Data)
+	assert.Equal(t, "second", (<-es.MessageEvents()).Data)
+	assert.Len(t, es.Errors(), 0, "advised reconnections must not be reported")
+	first, second := <-times, <-times
+	assert.True(t, second.Sub(first) >= 100*time.Millisecond, "advised delay not respected")
+}
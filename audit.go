@@ -0,0 +1,109 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+type (
+	// AuditMiddleware writes a JSON log line for every event flowing through it
+	// before forwarding the event to the consumer.
+	AuditMiddleware struct {
+		mu      sync.Mutex
+		dest    io.Writer
+		onError func(error)
+	}
+
+	auditRecord struct {
+		Timestamp       time.Time `json:"timestamp"`
+		SourceURL       string    `json:"source_url"`
+		EventID         string    `json:"event_id"`
+		EventName       string    `json:"event_name"`
+		DataLengthBytes int       `json:"data_length_bytes"`
+	}
+)
+
+// NewAuditLogger returns an AuditMiddleware writing to dest.
+// Writes to dest are serialized, hence the same middleware can wrap
+// several event sources or decoders at once.
+func NewAuditLogger(dest io.Writer) *AuditMiddleware {
+	return &AuditMiddleware{dest: dest}
+}
+
+// SetErrorHandler sets a function called with the errors met writing the
+// audit records. It must be called before wrapping anything.
+func (a *AuditMiddleware) SetErrorHandler(fn func(error)) {
+	a.onError = fn
+}
+
+// WrapEventSource returns a channel with the message events of es.
+// Every event is logged before being forwarded. The channel is closed once
+// es is closed, even if events are no longer received from it.
+func (a *AuditMiddleware) WrapEventSource(es *EventSource) <-chan *MessageEvent {
+	out := make(chan *MessageEvent)
+	go func() {
+		defer close(out)
+		for ev := range es.MessageEvents() {
+			a.log(es.URL(), ev)
+			select {
+			case out <- ev:
+			case <-es.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// WrapDecoder returns a channel with the events decoded by d.
+// Every event is logged with the given source URL before being forwarded.
+// The channel is closed once the input of d ends or d stops on an error, see
+// Decoder.Err, or once ctx is done, see Decoder.DecodeContext. Events reported
+// with a *ParseError in strict mode are skipped.
+func (a *AuditMiddleware) WrapDecoder(ctx context.Context, d *Decoder, sourceURL string) <-chan *MessageEvent {
+	out := make(chan *MessageEvent)
+	go func() {
+		defer close(out)
+		for {
+			ev, err := d.DecodeContext(ctx)
+			if err == io.EOF || d.Err() != nil {
+				return
+			} else if err != nil {
+				// Malformed event in strict mode, decoding goes on with the next one
+				continue
+			}
+			a.log(sourceURL, ev)
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// log writes the audit record of ev synchronously.
+// Failing to write the record is reported to the error handler, and does not
+// prevent the event from being forwarded.
+func (a *AuditMiddleware) log(sourceURL string, ev *MessageEvent) {
+	line, err := json.Marshal(auditRecord{
+		Timestamp:       time.Now().UTC(),
+		SourceURL:       sourceURL,
+		EventID:         ev.LastEventID,
+		EventName:       ev.Name,
+		DataLengthBytes: len(ev.Data),
+	})
+	if err == nil {
+		line = append(line, '\n')
+		a.mu.Lock()
+		_, err = a.dest.Write(line)
+		a.mu.Unlock()
+	}
+	if err != nil && a.onError != nil {
+		a.onError(err)
+	}
+}
@@ -0,0 +1,144 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-rfc/sse/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLoggerWrapDecoder(t *testing.T) {
+	out := new(bytes.Buffer)
+	audit := NewAuditLogger(out)
+
+	events := audit.WrapDecoder(context.Background(), newDecoder("id: 1\nevent: quote\ndata: AAPL\n\ndata: GOOG 1450\n\n"), "http://foo.com/stocks")
+	var received []*MessageEvent
+	for ev := range events {
+		received = append(received, ev)
+	}
+
+	records := readAuditRecords(t, out)
+	if assert.Len(t, records, 2) && assert.Len(t, received, 2) {
+		assert.Equal(t, "http://foo.com/stocks", records[0].SourceURL)
+		assert.Equal(t, "1", records[0].EventID)
+		assert.Equal(t, "quote", records[0].EventName)
+		assert.Equal(t, 4, records[0].DataLengthBytes)
+		assert.False(t, records[0].Timestamp.IsZero())
+		assert.Equal(t, 9, records[1].DataLengthBytes)
+	}
+}
+
+func TestAuditLoggerWrapDecoderInStrictMode(t *testing.T) {
+	out := new(bytes.Buffer)
+	in := bytes.NewBufferString("data: 1\n\nevent: a\r\n\ndata: 3\n\n")
+	decoder := NewDecoderWithOptions(in, WithLineEnding(LineEndingLFOnly), WithStrictMode())
+
+	var received []string
+	for ev := range NewAuditLogger(out).WrapDecoder(context.Background(), decoder, "") {
+		received = append(received, ev.Data)
+	}
+	assert.Equal(t, []string{"1", "3"}, received)
+	assert.Len(t, readAuditRecords(t, out), 2)
+	assert.NoError(t, decoder.Err())
+}
+
+func TestAuditLoggerWrapEventSource(t *testing.T) {
+	runTest(t, func(handler *testutils.TestServerHandler) {
+		es, err := NewEventSource(handler.URL)
+		assert.Nil(t, err)
+
+		out := new(bytes.Buffer)
+		events := NewAuditLogger(out).WrapEventSource(es)
+
+		expected := newMessageEvent("42", "", 128)
+		go handler.SendWithID(messageEventToString(expected), expected.LastEventID)
+
+		ev, ok := <-events
+		assert.True(t, ok)
+		assert.Equal(t, expected.Data, ev.Data)
+		es.Close(nil)
+
+		records := readAuditRecords(t, out)
+		if assert.Len(t, records, 1) {
+			assert.Equal(t, handler.URL, records[0].SourceURL)
+			assert.Equal(t, "42", records[0].EventID)
+			assert.Equal(t, 128, records[0].DataLengthBytes)
+		}
+	})
+}
+
+func TestAuditLoggerSerializesWrites(t *testing.T) {
+	out := new(bytes.Buffer)
+	audit := NewAuditLogger(out)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range audit.WrapDecoder(context.Background(), newDecoder(newMessageEventString("", "", 512)), "") {
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, readAuditRecords(t, out), 8)
+}
+
+func TestAuditLoggerStopsForwarding(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	go w.Write([]byte("data: first\n\ndata: second\n\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := NewAuditLogger(io.Discard).WrapDecoder(ctx, NewDecoder(r), "")
+	assert.Equal(t, "first", (<-events).Data)
+
+	// The consumer stops reading, the pending event is not forwarded
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	_, ok := <-events
+	assert.False(t, ok)
+}
+
+func TestAuditLoggerReportsWriteErrors(t *testing.T) {
+	errWrite := errors.New("disk full")
+	audit := NewAuditLogger(failingWriter{errWrite})
+	var errs []error
+	audit.SetErrorHandler(func(err error) { errs = append(errs, err) })
+
+	var received int
+	for range audit.WrapDecoder(context.Background(), newDecoder("data: first\n\n"), "") {
+		received++
+	}
+	assert.Equal(t, 1, received)
+	assert.Equal(t, []error{errWrite}, errs)
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func readAuditRecords(t *testing.T, in *bytes.Buffer) []auditRecord {
+	var records []auditRecord
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		var record auditRecord
+		if assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record)) {
+			records = append(records, record)
+		}
+	}
+	return records
+}
@@ -0,0 +1,102 @@
+package sse
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+type (
+	// ReconnectionStrategy decides how long to wait before every reconnection
+	// attempt, see WithReconnectionStrategy.
+	ReconnectionStrategy interface {
+		// NextDelay returns the time to wait before the given reconnection attempt,
+		// starting at 0, or false to give up reconnecting. serverRetry is the retry
+		// time sent by the server, 0 if none.
+		NextDelay(attempt int, serverRetry time.Duration) (time.Duration, bool)
+	}
+
+	// BackoffFunc returns the time to wait before the given reconnection attempt,
+	// starting at 0, see WithBackoff.
+	BackoffFunc func(attempt int) time.Duration
+
+	noReconnection struct{}
+)
+
+// NoReconnection is a ReconnectionStrategy never reconnecting.
+var NoReconnection ReconnectionStrategy = noReconnection{}
+
+var (
+	jitterMutex sync.Mutex
+	jitterRand  = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// WithReconnectionStrategy sets the strategy deciding how long to wait before
+// every reconnection attempt, and when to give up reconnecting, e.g. to implement
+// a circuit breaker. Giving up closes the event source with an *SSEError of code
+// ErrCodeRetryExhausted.
+// It replaces WithBackoff and WithExponentialBackoff.
+func WithReconnectionStrategy(strategy ReconnectionStrategy) EventSourceOption {
+	return func(es *EventSource) {
+		es.strategy = strategy
+	}
+}
+
+// WithBackoff sets the strategy computing the delay between reconnection attempts,
+// e.g. FullJitterBackoff so that many clients disconnected at once do not reconnect
+// at the same time. The retry time sent by the server, if any, is the minimum delay.
+// It replaces WithExponentialBackoff.
+func WithBackoff(fn BackoffFunc) EventSourceOption {
+	return WithReconnectionStrategy(fn)
+}
+
+// NextDelay returns the delay computed by f, unless the retry time sent by the
+// server is greater. It always reconnects.
+func (f BackoffFunc) NextDelay(attempt int, serverRetry time.Duration) (time.Duration, bool) {
+	delay := f(attempt)
+	if serverRetry > delay {
+		delay = serverRetry
+	}
+	return delay, true
+}
+
+// ConstantBackoff waits d before every reconnection attempt.
+func ConstantBackoff(d time.Duration) BackoffFunc {
+	return func(int) time.Duration {
+		return d
+	}
+}
+
+// ExponentialBackoff waits base before the first reconnection attempt, then doubles
+// the delay on every failed attempt up to max.
+func ExponentialBackoff(base, max time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		delay := base
+		for i := 0; i < attempt && delay < max; i++ {
+			delay *= 2
+		}
+		if delay > max {
+			delay = max
+		}
+		return delay
+	}
+}
+
+// FullJitterBackoff waits a random delay between 0 and the delay of
+// ExponentialBackoff(base, max).
+func FullJitterBackoff(base, max time.Duration) BackoffFunc {
+	exponential := ExponentialBackoff(base, max)
+	return func(attempt int) time.Duration {
+		delay := exponential(attempt)
+		if delay <= 0 {
+			return 0
+		}
+		jitterMutex.Lock()
+		defer jitterMutex.Unlock()
+		return time.Duration(jitterRand.Int63n(int64(delay) + 1))
+	}
+}
+
+func (noReconnection) NextDelay(int, time.Duration) (time.Duration, bool) {
+	return 0, false
+}
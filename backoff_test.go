@@ -0,0 +1,57 @@
+package sse
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFullJitterBackoff(t *testing.T) {
+	backoff := FullJitterBackoff(100*time.Millisecond, time.Second)
+	delays := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		delay := backoff(3)
+		assert.True(t, delay >= 0 && delay <= 800*time.Millisecond, "unexpected delay %v", delay)
+		delays[delay] = true
+	}
+	assert.Greater(t, len(delays), 1, "delays must be randomized")
+	assert.True(t, backoff(1000) <= time.Second)
+}
+
+func TestEventSourceWithBackoff(t *testing.T) {
+	es := &EventSource{d: NewDecoder(nil)}
+	delay := func(attempt int) time.Duration {
+		d, _ := es.reconnectDelay(attempt)
+		return d
+	}
+	WithExponentialBackoff(time.Second, time.Minute)(es)
+	WithBackoff(func(attempt int) time.Duration {
+		return time.Duration(attempt) * 100 * time.Millisecond
+	})(es)
+	assert.Equal(t, 0*time.Millisecond, delay(0))
+	assert.Equal(t, 500*time.Millisecond, delay(5))
+
+	// The retry time sent by the server is the minimum
+	es.d = NewDecoder(strings.NewReader("retry: 300\n\n"))
+	es.d.Decode()
+	assert.Equal(t, 300*time.Millisecond, delay(0))
+	assert.Equal(t, 500*time.Millisecond, delay(5))
+}
+
+func TestReconnectionStrategies(t *testing.T) {
+	delay, ok := ConstantBackoff(time.Second).NextDelay(10, 0)
+	assert.Equal(t, time.Second, delay)
+	assert.True(t, ok)
+	delay, _ = ConstantBackoff(time.Second).NextDelay(10, time.Minute)
+	assert.Equal(t, time.Minute, delay)
+
+	_, ok = NoReconnection.NextDelay(0, 0)
+	assert.False(t, ok)
+
+	es := &EventSource{d: NewDecoder(nil)}
+	WithReconnectionStrategy(NoReconnection)(es)
+	_, ok = es.reconnectDelay(0)
+	assert.False(t, ok)
+}
@@ -0,0 +1,86 @@
+package sse
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	events := []*MessageEvent{
+		eventFull,
+		{Data: "multi\nline\ndata"},
+		{Name: "empty"},
+		{},
+	}
+	out := new(bytes.Buffer)
+	w := NewBinaryWriter(out)
+	for _, ev := range events {
+		assert.NoError(t, w.WriteEvent(ev))
+	}
+
+	d := NewBinaryDecoder(out)
+	var decoded []*MessageEvent
+	for ev := range d.Decode() {
+		decoded = append(decoded, ev)
+	}
+	assert.Equal(t, events, decoded)
+	assert.NoError(t, d.Err())
+}
+
+func TestBinaryDecoderTruncatedInput(t *testing.T) {
+	out := new(bytes.Buffer)
+	NewBinaryWriter(out).WriteEvent(eventFull)
+
+	d := NewBinaryDecoder(bytes.NewReader(out.Bytes()[:out.Len()-3]))
+	for range d.Decode() {
+		assert.Fail(t, "truncated event decoded")
+	}
+	assert.Equal(t, io.ErrUnexpectedEOF, d.Err())
+}
+
+func TestBinaryDecoderNext(t *testing.T) {
+	out := new(bytes.Buffer)
+	w := NewBinaryWriter(out)
+	w.WriteEvent(eventFull)
+	w.WriteEvent(&MessageEvent{Data: "second"})
+
+	d := NewBinaryDecoder(bytes.NewReader(out.Bytes()))
+	ev, err := d.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, eventFull, ev)
+	ev, err = d.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "second", ev.Data)
+	_, err = d.Next()
+	assert.Equal(t, io.EOF, err)
+
+	d = NewBinaryDecoder(bytes.NewReader(out.Bytes()[:out.Len()-3]))
+	d.Next()
+	_, err = d.Next()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func BenchmarkEncodeText(b *testing.B) {
+	out := new(bytes.Buffer)
+	e := NewEncoder(out)
+	ev := &MessageEvent{LastEventID: "1042", Name: "temperature", Data: `{"sensor":"a7","celsius":21.5}`}
+	for i := 0; i < b.N; i++ {
+		out.Reset()
+		e.Write(ev)
+	}
+	b.ReportMetric(float64(out.Len()), "bytes/event")
+}
+
+func BenchmarkEncodeBinary(b *testing.B) {
+	out := new(bytes.Buffer)
+	w := NewBinaryWriter(out)
+	ev := &MessageEvent{LastEventID: "1042", Name: "temperature", Data: `{"sensor":"a7","celsius":21.5}`}
+	for i := 0; i < b.N; i++ {
+		out.Reset()
+		w.WriteEvent(ev)
+	}
+	b.ReportMetric(float64(out.Len()), "bytes/event")
+}
@@ -0,0 +1,19 @@
+/*
+
+Package brotli decodes server-sent events streams compressed with Brotli
+(Content-Encoding: br).
+
+*/
+package brotli
+
+import (
+	"io"
+
+	br "github.com/andybalholm/brotli"
+	"github.com/go-rfc/sse"
+)
+
+// NewBrotliDecoder returns a Decoder reading events from the Brotli compressed input r.
+func NewBrotliDecoder(r io.Reader, opts ...sse.DecoderOption) *sse.Decoder {
+	return sse.NewDecoderWithOptions(br.NewReader(r), opts...)
+}
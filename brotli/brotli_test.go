@@ -0,0 +1,38 @@
+package brotli
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	br "github.com/andybalholm/brotli"
+	"github.com/go-rfc/sse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBrotliDecoder(t *testing.T) {
+	compressed := new(bytes.Buffer)
+	w := br.NewWriter(compressed)
+	w.Write([]byte("id: 1\nevent: quote\ndata: {\"symbol\": \"AAPL\"}\n\n: keep-alive\n\ndata: multi\ndata: line\n\n"))
+	w.Close()
+
+	decoder := NewBrotliDecoder(compressed)
+	var events []*sse.MessageEvent
+	for {
+		ev, err := decoder.Decode()
+		if err != nil {
+			assert.Equal(t, io.EOF, err)
+			break
+		}
+		events = append(events, ev)
+	}
+	assert.Equal(t, []*sse.MessageEvent{
+		{LastEventID: "1", Name: "quote", Data: `{"symbol": "AAPL"}`},
+		{LastEventID: "1", Data: "multi\nline"},
+	}, events)
+}
+
+func TestBrotliDecoderCorruptedInput(t *testing.T) {
+	_, err := NewBrotliDecoder(bytes.NewReader([]byte("data: not compressed\n\n"))).Decode()
+	assert.True(t, err != nil && err != io.EOF)
+}
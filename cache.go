@@ -0,0 +1,116 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Default time given to the event cache to answer, see WithEventCacheTimeout.
+const defaultCacheTimeout = 5 * time.Second
+
+// cachedEvent is an event returned by the event cache.
+type cachedEvent struct {
+	ID    string `json:"id"`
+	Event string `json:"event"`
+	Data  string `json:"data"`
+}
+
+// WithEventCache recovers the events missed while reconnecting, for servers unable to
+// replay them from the Last-Event-ID header.
+// On every reconnection, a GET request is sent to fetchURL with the last event id in
+// the since query parameter. The response must be a JSON array of objects with id,
+// event and data string fields. Up to capacity of the most recent of these events
+// are delivered before the events of the new stream.
+// Failing to fetch the events is reported on Errors and the stream is consumed anyway.
+func WithEventCache(capacity int, fetchURL string) EventSourceOption {
+	return func(es *EventSource) {
+		es.cacheCapacity = capacity
+		es.cacheURL = fetchURL
+	}
+}
+
+// WithEventCacheTimeout sets the time given to the event cache to answer, five
+// seconds by default. The events of the new stream are held until then.
+func WithEventCacheTimeout(d time.Duration) EventSourceOption {
+	return func(es *EventSource) {
+		es.cacheTimeout = d
+	}
+}
+
+// replayCache delivers the events missed since the last event id.
+// It returns false if the event source was closed meanwhile.
+func (es *EventSource) replayCache() bool {
+	events, err := es.fetchCache()
+	if err != nil {
+		es.logf("eventsource: cannot fetch missed events from %s: %v", es.cacheURL, err)
+		es.notifyError(err)
+		return true
+	}
+	for _, ev := range events {
+		if !es.dispatch(ev) {
+			return false
+		}
+	}
+	return true
+}
+
+func (es *EventSource) fetchCache() ([]*MessageEvent, error) {
+	u, err := url.Parse(es.cacheURL)
+	if err != nil {
+		return nil, &SSEError{Code: ErrCodeNetwork, Message: "cannot create event cache request", Cause: err}
+	}
+	query := u.Query()
+	query.Set("since", es.lastEventID)
+	u.RawQuery = query.Encode()
+
+	timeout := es.cacheTimeout
+	if timeout <= 0 {
+		timeout = defaultCacheTimeout
+	}
+	ctx, cancel := context.WithTimeout(es.ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, &SSEError{Code: ErrCodeNetwork, Message: "cannot create event cache request", Cause: err}
+	}
+	req.Header.Set("Accept", "application/json")
+	// The cache serves the same events, with the same credentials, as the stream
+	if err = es.prepareRequest(req, false); err != nil {
+		return nil, err
+	}
+	resp, err := es.client.Do(req)
+	if err != nil {
+		return nil, &SSEError{Code: ErrCodeNetwork, Message: "cannot fetch missed events", Cause: err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, &SSEError{
+			Code:       ErrCodeHTTPStatus,
+			StatusCode: resp.StatusCode,
+			Message:    "cannot fetch missed events",
+			Cause:      &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, URL: es.cacheURL},
+		}
+	}
+
+	var cached []cachedEvent
+	if err := json.NewDecoder(resp.Body).Decode(&cached); err != nil {
+		return nil, &SSEError{Code: ErrCodeProtocol, Message: "malformed missed events", Cause: err}
+	}
+	if es.cacheCapacity > 0 && len(cached) > es.cacheCapacity {
+		cached = cached[len(cached)-es.cacheCapacity:]
+	}
+	events := make([]*MessageEvent, len(cached))
+	lastEventID := es.lastEventID
+	for i, c := range cached {
+		if c.ID != "" {
+			lastEventID = c.ID
+		}
+		events[i] = &MessageEvent{LastEventID: lastEventID, Name: c.Event, Data: c.Data}
+	}
+	return events, nil
+}
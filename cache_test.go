@@ -0,0 +1,90 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourceEventCache(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		if req.Header.Get("Last-Event-ID") == "" {
+			rw.Write([]byte("retry: 1\nid: 1\ndata: live-1\n\n"))
+			return
+		}
+		assert.Equal(t, "1", req.Header.Get("Last-Event-ID"))
+		rw.Write([]byte("id: 4\ndata: live-4\n\n"))
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	})
+	mux.HandleFunc("/cache", func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "1", req.URL.Query().Get("since"))
+		assert.Equal(t, "acme", req.Header.Get("X-Tenant"))
+		rw.Write([]byte(`[{"id": "0", "data": "evicted"}, {"id": "2", "data": "missed-2"}, {"event": "x", "data": "missed-3"}]`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL+"/events", WithEventCache(2, server.URL+"/cache"), WithHeader("X-Tenant", "acme"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+
+	for _, expected := range []*MessageEvent{
+		{LastEventID: "1", Data: "live-1"},
+		{LastEventID: "2", Data: "missed-2"},
+		{LastEventID: "2", Name: "x", Data: "missed-3"},
+		{LastEventID: "4", Data: "live-4"},
+	} {
+		select {
+		case ev := <-es.MessageEvents():
+			assert.Equal(t, expected, ev)
+		case <-time.After(time.Second):
+			assert.FailNow(t, "event not received", expected.Data)
+		}
+	}
+}
+
+func TestEventSourceEventCacheFailure(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("retry: 1\ndata: live\n\n"))
+	})
+	mux.HandleFunc("/cache", func(rw http.ResponseWriter, req *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL+"/events", WithEventCache(10, server.URL+"/cache"), WithEventCacheTimeout(10*time.Millisecond))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+
+	// The live events keep flowing after the cache timed out
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, "live", (<-es.MessageEvents()).Data)
+	}
+	for err := range es.Errors() {
+		if err != ErrStreamClosed {
+			assert.True(t, hasErrorCode(err, ErrCodeNetwork), err.Error())
+			break
+		}
+	}
+}
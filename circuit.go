@@ -0,0 +1,43 @@
+package sse
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrCircuitOpen error is the ReadyState error of an event source waiting for
+	// the cool-down period of its circuit breaker, see WithCircuitBreaker.
+	ErrCircuitOpen = errors.New("eventsource: circuit open")
+)
+
+// WithCircuitBreaker stops reconnecting for the coolDown period once threshold
+// consecutive connection attempts failed, so that a fleet of clients does not
+// hammer a recovering server. While the circuit is open, the ready state is
+// Connecting with ErrCircuitOpen as error.
+// After the cool-down period, a single attempt is made: the circuit is opened
+// again if it fails. A threshold of 0 disables the circuit breaker.
+func WithCircuitBreaker(threshold int, coolDown time.Duration) EventSourceOption {
+	return func(es *EventSource) {
+		es.circuitThreshold = threshold
+		es.circuitCoolDown = coolDown
+	}
+}
+
+// waitCircuit blocks for the cool-down period if the circuit breaker is open.
+// It returns false if the event source is closed meanwhile.
+func (es *EventSource) waitCircuit() bool {
+	if es.circuitThreshold <= 0 {
+		return true
+	}
+	es.stateMutex.RLock()
+	failures := es.consecutiveFailures
+	es.stateMutex.RUnlock()
+	if failures < uint32(es.circuitThreshold) {
+		return true
+	}
+
+	es.logf("eventsource: %d consecutive connection failures, circuit open for %v", failures, es.circuitCoolDown)
+	es.setReadyState(Status{Connecting, ErrCircuitOpen})
+	return es.sleep(es.circuitCoolDown)
+}
@@ -0,0 +1,55 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourceWithCircuitBreaker(t *testing.T) {
+	var requests int32
+	times := make(chan time.Time, 5)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		times <- time.Now()
+		if atomic.AddInt32(&requests, 1) > 1 {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("retry: 1\n\n"))
+	}))
+	defer server.Close()
+
+	coolDown := 300 * time.Millisecond
+	es, err := NewEventSource(server.URL,
+		WithStatusPolicy(DefaultStatusPolicy),
+		WithCircuitBreaker(2, coolDown),
+		WithMaxReconnectAttempts(4))
+	if !assert.NoError(t, err) {
+		return
+	}
+	circuitOpen := make(chan struct{}, 10)
+	go func() {
+		for status := range es.ReadyState() {
+			if status.Err == ErrCircuitOpen {
+				circuitOpen <- struct{}{}
+			}
+		}
+	}()
+	<-es.Done()
+
+	assert.Equal(t, int32(5), atomic.LoadInt32(&requests))
+	assert.True(t, hasErrorCode(es.Err(), ErrCodeRetryExhausted))
+	// Opened after the second and third failures
+	assert.Len(t, circuitOpen, 2)
+
+	<-times
+	failed1, failed2, probe1, probe2 := <-times, <-times, <-times, <-times
+	assert.True(t, failed2.Sub(failed1) < coolDown, "circuit opened too early")
+	assert.True(t, probe1.Sub(failed2) >= coolDown, "cool-down not respected")
+	assert.True(t, probe2.Sub(probe1) >= coolDown, "circuit not opened again")
+}
@@ -0,0 +1,138 @@
+package sse
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type (
+	// Config builds an EventSource from chainable settings, as an alternative
+	// to the EventSourceOption functions:
+	//
+	//	es, err := sse.Config{}.URL(url).DialTimeout(5 * time.Second).Build()
+	Config struct {
+		url         string
+		client      *http.Client
+		clientSet   bool
+		dialTimeout *time.Duration
+		backoffBase time.Duration
+		backoffMax  time.Duration
+		backoff     bool
+		logger      *log.Logger
+	}
+
+	// FieldError describes an invalid Config field.
+	FieldError struct {
+		Field   string
+		Message string
+	}
+
+	// ValidationError collects all the invalid fields of a Config.
+	ValidationError struct {
+		Errors []FieldError
+	}
+)
+
+// URL sets the URL of the stream.
+func (c Config) URL(url string) Config {
+	c.url = url
+	return c
+}
+
+// HTTPClient sets the client used to connect to the stream, see WithHTTPClient.
+func (c Config) HTTPClient(client *http.Client) Config {
+	c.client = client
+	c.clientSet = true
+	return c
+}
+
+// DialTimeout limits the time spent establishing connections, see WithDialTimeout.
+func (c Config) DialTimeout(d time.Duration) Config {
+	c.dialTimeout = &d
+	return c
+}
+
+// Backoff sets an exponential backoff between reconnections, see WithExponentialBackoff.
+func (c Config) Backoff(base, max time.Duration) Config {
+	c.backoffBase = base
+	c.backoffMax = max
+	c.backoff = true
+	return c
+}
+
+// Logger sets the logger, see WithLogger.
+func (c Config) Logger(logger *log.Logger) Config {
+	c.logger = logger
+	return c
+}
+
+// Build validates the configuration and connects the EventSource.
+// If the configuration is invalid, a *ValidationError reporting every invalid
+// field is returned.
+func (c Config) Build() (*EventSource, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return NewEventSource(c.url, c.options()...)
+}
+
+func (c Config) validate() error {
+	var errs []FieldError
+	if c.url == "" {
+		errs = append(errs, FieldError{"URL", "must not be empty"})
+	} else if u, err := url.Parse(c.url); err != nil {
+		errs = append(errs, FieldError{"URL", err.Error()})
+	} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, FieldError{"URL", "must be an absolute http or https URL"})
+	}
+	if c.clientSet && c.client == nil {
+		errs = append(errs, FieldError{"HTTPClient", "must not be nil"})
+	}
+	if c.dialTimeout != nil && *c.dialTimeout <= 0 {
+		errs = append(errs, FieldError{"DialTimeout", "must be positive"})
+	}
+	if c.backoff {
+		if c.backoffBase <= 0 {
+			errs = append(errs, FieldError{"Backoff", "base must be positive"})
+		}
+		if c.backoffMax < c.backoffBase {
+			errs = append(errs, FieldError{"Backoff", "max must not be lower than base"})
+		}
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func (c Config) options() []EventSourceOption {
+	var opts []EventSourceOption
+	if c.clientSet {
+		opts = append(opts, WithHTTPClient(c.client))
+	}
+	if c.dialTimeout != nil {
+		opts = append(opts, WithDialTimeout(*c.dialTimeout))
+	}
+	if c.backoff {
+		opts = append(opts, WithExponentialBackoff(c.backoffBase, c.backoffMax))
+	}
+	if c.logger != nil {
+		opts = append(opts, WithLogger(c.logger))
+	}
+	return opts
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fieldErr := range e.Errors {
+		msgs[i] = fieldErr.Error()
+	}
+	return "eventsource: invalid config: " + strings.Join(msgs, "; ")
+}
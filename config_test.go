@@ -0,0 +1,99 @@
+package sse
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigBuildReportsAllErrors(t *testing.T) {
+	_, err := Config{}.URL("ftp://example.com").DialTimeout(-time.Second).Backoff(time.Second, time.Millisecond).Build()
+
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(err, &validationErr)) {
+		assert.Equal(t, []FieldError{
+			{"URL", "must be an absolute http or https URL"},
+			{"DialTimeout", "must be positive"},
+			{"Backoff", "max must not be lower than base"},
+		}, validationErr.Errors)
+	}
+}
+
+func TestConfigBuildRequiresURL(t *testing.T) {
+	_, err := Config{}.Build()
+	assert.EqualError(t, err, "eventsource: invalid config: URL: must not be empty")
+}
+
+func TestConfigBuildConnects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := &http.Client{}
+	es, err := Config{}.URL(server.URL).HTTPClient(client).DialTimeout(time.Second).Backoff(10*time.Millisecond, time.Second).Build()
+	if assert.NoError(t, err) {
+		assert.Equal(t, server.URL, es.URL())
+		assert.NotSame(t, client, es.client, "dial timeout must not modify the given client")
+		assert.Equal(t, 10*time.Millisecond, es.backoffBase)
+	}
+}
+
+func TestEventSourceReconnectDelay(t *testing.T) {
+	es := &EventSource{d: NewDecoder(nil)}
+	delay := func(attempt int) time.Duration {
+		d, _ := es.reconnectDelay(attempt)
+		return d
+	}
+	assert.Equal(t, defaultRetry*time.Millisecond, delay(3))
+
+	WithExponentialBackoff(100*time.Millisecond, time.Second)(es)
+	for attempt, expected := range []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	} {
+		assert.Equal(t, expected, delay(attempt))
+	}
+	assert.Equal(t, time.Second, delay(1000))
+
+	// The retry time sent by the server is the minimum
+	es.d = NewDecoder(strings.NewReader("retry: 300\n\n"))
+	es.d.Decode()
+	assert.Equal(t, 300*time.Millisecond, delay(0))
+	assert.Equal(t, 400*time.Millisecond, delay(2))
+}
+
+func TestEventSourceBackoffRespectsServerRetry(t *testing.T) {
+	connections := make(chan time.Time, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		connections <- time.Now()
+		rw.Header().Set("Content-Type", allowedContentType)
+		if len(connections) == 1 {
+			// Force a reconnection
+			rw.Write([]byte("retry: 1000\ndata: first\n\n"))
+			return
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL, WithExponentialBackoff(10*time.Millisecond, 5*time.Second))
+	if !assert.NoError(t, err) {
+		return
+	}
+	go discardMessageEvents(es)
+	collectStates(es.ReadyState())
+
+	first, second := <-connections, <-connections
+	assert.True(t, second.Sub(first) >= time.Second, "reconnected after %v", second.Sub(first))
+}
@@ -0,0 +1,65 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrConnectTimeout error indicates the response headers were not received
+	// within the duration given to WithConnectTimeout.
+	ErrConnectTimeout = errors.New("eventsource: no response received before the connect timeout")
+)
+
+// WithConnectTimeout limits the time spent connecting to the server and receiving
+// the response headers, on every connection attempt. Unlike the Timeout of the
+// HTTP client, it does not apply to reading the stream, which may last forever.
+// Attempts which time out fail with ErrConnectTimeout.
+func WithConnectTimeout(d time.Duration) EventSourceOption {
+	return func(es *EventSource) {
+		es.connectTimeout = d
+	}
+}
+
+// do sends req with the client of the event source, applying the connect timeout.
+func (es *EventSource) do(req *http.Request) (*http.Response, error) {
+	timeout := es.connectTimeout
+	// Reconnection attempts must not outlast the reconnection budget
+	if remaining := time.Until(es.connectDeadline); remaining > 0 && (timeout <= 0 || remaining < timeout) {
+		timeout = remaining
+	}
+	if timeout <= 0 {
+		return es.client.Do(req)
+	}
+	ctx, cancel := context.WithCancel(req.Context())
+	timer := time.AfterFunc(timeout, cancel)
+	resp, err := es.client.Do(req.WithContext(ctx))
+	if !timer.Stop() {
+		if err == nil {
+			resp.Body.Close()
+		}
+		cancel()
+		return nil, ErrConnectTimeout
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The context must live as long as the stream is read
+	resp.Body = &cancelReadCloser{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelReadCloser cancels the context of the request once its body is closed.
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelReadCloser) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
@@ -0,0 +1,49 @@
+package sse
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourceWithConnectTimeout(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-req.Context().Done():
+		}
+	}))
+	defer slow.Close()
+
+	start := time.Now()
+	_, err := NewEventSource(slow.URL, WithConnectTimeout(100*time.Millisecond))
+	assert.True(t, errors.Is(err, ErrConnectTimeout), "unexpected error %v", err)
+	assert.True(t, hasErrorCode(err, ErrCodeNetwork))
+	assert.True(t, time.Since(start) < time.Second, "connect timeout not applied")
+
+	// The timeout does not apply to the stream
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.(http.Flusher).Flush()
+		time.Sleep(300 * time.Millisecond)
+		rw.Write([]byte("data: late\n\n"))
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL, WithConnectTimeout(100*time.Millisecond))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+	assert.Equal(t, "late", (<-es.MessageEvents()).Data)
+}
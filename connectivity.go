@@ -0,0 +1,49 @@
+package sse
+
+import "errors"
+
+var (
+	// ErrOffline error is the ReadyState error of an event source waiting for the
+	// network to be available before reconnecting, see WithConnectivity.
+	ErrOffline = errors.New("eventsource: offline")
+)
+
+// Connectivity reports whether the network is available, e.g. from the network
+// state notifications of the operating system.
+type Connectivity interface {
+	// Online returns a channel which is closed once the network is available,
+	// hence already closed while it is.
+	Online() <-chan struct{}
+}
+
+// WithConnectivity makes the event source wait for the network to be available
+// before every reconnection attempt, instead of failing attempts which are
+// bound to fail. While waiting, the ready state is Connecting with ErrOffline as
+// error, and no reconnection attempt is counted.
+func WithConnectivity(c Connectivity) EventSourceOption {
+	return func(es *EventSource) {
+		es.connectivity = c
+	}
+}
+
+// waitOnline blocks until the network is available.
+// It returns false if the event source is closed meanwhile.
+func (es *EventSource) waitOnline() bool {
+	if es.connectivity == nil {
+		return true
+	}
+	online := es.connectivity.Online()
+	select {
+	case <-online:
+		return true
+	default:
+	}
+	es.logf("eventsource: offline, waiting for the network to reconnect to %s", es.url)
+	es.setReadyState(Status{Connecting, ErrOffline})
+	select {
+	case <-online:
+		return true
+	case <-es.closing:
+		return false
+	}
+}
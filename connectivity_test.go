@@ -0,0 +1,43 @@
+package sse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// channelConnectivity is online once its channel is closed.
+type channelConnectivity chan struct{}
+
+func (c channelConnectivity) Online() <-chan struct{} {
+	return c
+}
+
+func TestEventSourceWithConnectivity(t *testing.T) {
+	server, requests := newReconnectingServer(1)
+	defer server.Close()
+
+	connectivity := make(channelConnectivity)
+	es, err := NewEventSource(server.URL, WithConnectivity(connectivity))
+	if !assert.NoError(t, err) {
+		return
+	}
+	offline := make(chan struct{})
+	go func() {
+		for status := range es.ReadyState() {
+			if status.Err == ErrOffline {
+				close(offline)
+			}
+		}
+	}()
+	go discardMessageEvents(es)
+
+	<-offline
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, requests, 1, "reconnected while offline")
+	close(connectivity)
+	<-es.Done()
+	assert.Len(t, requests, 2)
+	assert.Equal(t, ErrNoContent, es.Err())
+}
@@ -3,54 +3,338 @@ package sse
 import (
 	"bufio"
 	"bytes"
+	"errors"
+	"fmt"
 	"io"
-	"strconv"
+	"math"
 	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
 )
 
 // Default retry time in milliseconds.
 // The spec recommends to use a value of a few seconds.
 const defaultRetry = 2500
 
+var (
+	// ErrUnexpectedLineEnding error indicates a line was terminated differently than
+	// the line ending mode of the decoder expects.
+	ErrUnexpectedLineEnding = errors.New("decoder: unexpected line ending")
+	// ErrTooManyDataLines error indicates an event exceeded the number of data lines
+	// allowed by WithMaxDataLines.
+	ErrTooManyDataLines = errors.New("decoder: too many data lines")
+)
+
 type (
 	// Decoder accepts an io.Reader input and decodes message events from it.
 	Decoder struct {
-		lastEventID string
-		retry       int
+		stats       DecoderStats // Accessed atomically, kept first for 64-bit alignment
+		partial     PartialEvent
+		parsers     map[string]FieldParser
+		in          io.Reader
+		reader      *contextReader
+		opts        []DecoderOption
 		scanner     *bufio.Scanner
-		data        *bytes.Buffer
+		line        int
+		maxLineSize int
+		lineErr     error
+		discarding  bool
+		onError     func(error)
+		lineEnding  LineEndingMode
+		strict      bool
+		dedup       *idWindow
+		window      *eventWindow
+		now         func() time.Time
+		filters     []func(*MessageEvent) bool
+		limiter     *rate.Limiter
+		maxData     int
+		idVersion   *int
+		bufferSize  int
+		split       bufio.SplitFunc
+		// Error which stopped decoding, see Err
+		err error
+		// Set while skipping the rest of a dropped event, across Decode calls
+		skipping bool
+	}
+
+	// DecoderStats holds counters about the events processed by a Decoder.
+	DecoderStats struct {
+		// DuplicatesDropped counts the events dropped by WithDeduplication and WithEventWindow.
+		DuplicatesDropped uint64
+		// DroppedByRateLimiter counts the events dropped by WithRateLimit.
+		DroppedByRateLimiter uint64
+		// DroppedByDataLineLimit counts the events dropped by WithMaxDataLines.
+		DroppedByDataLineLimit uint64
+	}
+
+	// DecoderOption configures a Decoder.
+	DecoderOption func(*Decoder)
+
+	// ParseError is returned by a Decoder in strict mode when a malformed line is found.
+	ParseError struct {
+		// Line number in the stream, starting at 1.
+		Line      int
+		Content   string
+		FieldName string
+		Cause     error
 	}
 )
 
+// WithBufferSize makes the Decoder use a fixed buffer of bufferSize bytes, which
+// limits the length of the lines.
+// Values lower or equal to 0 keep the default growing buffer.
+func WithBufferSize(bufferSize int) DecoderOption {
+	return func(d *Decoder) {
+		d.bufferSize = bufferSize
+	}
+}
+
+// WithSplitFunc replaces the function splitting the input into lines.
+// It takes precedence over WithLineEnding.
+func WithSplitFunc(split bufio.SplitFunc) DecoderOption {
+	return func(d *Decoder) {
+		d.split = split
+	}
+}
+
+// WithStrictMode makes the Decoder return a *ParseError on malformed input instead
+// of leniently accepting it.
+func WithStrictMode() DecoderOption {
+	return func(d *Decoder) {
+		d.strict = true
+	}
+}
+
+// WithLineEnding restricts the line terminators recognized by the Decoder.
+// Unexpected terminators are kept as part of the line, unless strict mode is enabled.
+func WithLineEnding(mode LineEndingMode) DecoderOption {
+	return func(d *Decoder) {
+		d.lineEnding = mode
+	}
+}
+
+// WithDeduplication drops events whose id was already seen within the last
+// windowSize events carrying an id. Events without an id always pass through.
+func WithDeduplication(windowSize int) DecoderOption {
+	return func(d *Decoder) {
+		if windowSize > 0 {
+			d.dedup = newIDWindow(windowSize)
+		}
+	}
+}
+
+// WithEventWindow drops the events identical to an event dispatched less than window ago,
+// comparing their id, name and data. Unlike WithDeduplication, it applies to events
+// without an id too, e.g. for producers publishing twice with at-least-once delivery.
+func WithEventWindow(window time.Duration) DecoderOption {
+	return func(d *Decoder) {
+		if window > 0 {
+			d.window = newEventWindow(window)
+		}
+	}
+}
+
+// WithFilter drops the events for which fn returns false.
+// fn is only called with fully parsed events about to be dispatched.
+// When given several times, all the filters must accept an event.
+func WithFilter(fn func(*MessageEvent) bool) DecoderOption {
+	return func(d *Decoder) {
+		d.filters = append(d.filters, fn)
+	}
+}
+
+// WithRateLimit drops the events exceeding eventsPerSecond instead of blocking the
+// decoding until the consumer catches up. Bursts of up to one second worth of events
+// are allowed.
+func WithRateLimit(eventsPerSecond float64) DecoderOption {
+	return func(d *Decoder) {
+		burst := int(math.Ceil(eventsPerSecond))
+		if burst < 1 {
+			burst = 1
+		}
+		d.limiter = rate.NewLimiter(rate.Limit(eventsPerSecond), burst)
+	}
+}
+
+// WithMaxDataLines drops the events with more than n data lines, to protect against
+// streams growing an event indefinitely. The rest of the event is skipped.
+// In strict mode, a *ParseError is returned instead.
+// The default of 0 does not limit the number of lines.
+func WithMaxDataLines(n int) DecoderOption {
+	return func(d *Decoder) {
+		d.maxData = n
+	}
+}
+
+// WithErrorRecovery makes the Decoder skip malformed events instead of stopping.
+// Lines exceeding the buffer size (bufio.ErrTooLong) and, in strict mode, parse errors
+// are reported to onError; the partially parsed event is discarded and decoding resumes
+// after the next empty line. Errors reading the input are still returned by Decode.
+func WithErrorRecovery(onError func(err error)) DecoderOption {
+	return func(d *Decoder) {
+		d.onError = onError
+	}
+}
+
+// NewDecoder returns a Decoder with a growing buffer.
+// Lines are limited to bufio.MaxScanTokenSize - 1.
+func NewDecoder(in io.Reader) *Decoder {
+	return NewDecoderWithOptions(in)
+}
+
+// NewDecoderSize returns a Decoder with a fixed buffer size.
+// It is a shorthand for NewDecoderWithOptions(in, WithBufferSize(bufferSize)).
+func NewDecoderSize(in io.Reader, bufferSize int) *Decoder {
+	return NewDecoderWithOptions(in, WithBufferSize(bufferSize))
+}
+
+// NewDecoderWithOptions returns a Decoder configured with the given options.
+// Unless WithBufferSize is given, the buffer grows as needed and lines are limited
+// to bufio.MaxScanTokenSize - 1.
+func NewDecoderWithOptions(in io.Reader, opts ...DecoderOption) *Decoder {
+	d := &Decoder{
+		partial:     PartialEvent{data: new(bytes.Buffer), retry: defaultRetry},
+		in:          in,
+		reader:      &contextReader{r: in}, // See decoder_context.go
+		opts:        opts,
+		maxLineSize: bufio.MaxScanTokenSize,
+		now:         time.Now,
+	}
+	d.scanner = bufio.NewScanner(d.reader)
+	d.registerBuiltinParsers() // See field_parser.go
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.bufferSize > 0 {
+		d.scanner.Buffer(make([]byte, d.bufferSize), d.bufferSize)
+		d.maxLineSize = d.bufferSize
+	}
+	split := d.split
+	if split == nil {
+		split = d.lineEnding.splitFunc() // See scanlines.go
+	}
+	if d.onError != nil {
+		split = d.recoveringSplit(split)
+	}
+	d.scanner.Split(split)
+	return d
+}
+
+// Tee returns a Decoder configured like d whose input is also written to
+// secondary as it is read, e.g. to archive the raw stream while decoding it.
+// secondary receives all the bytes, including comments and blank lines.
+// Tee must be called before decoding, and d must not be used afterwards.
+func (d *Decoder) Tee(secondary io.Writer) *Decoder {
+	tee := NewDecoderWithOptions(io.TeeReader(d.in, secondary), d.opts...)
+	for name, p := range d.parsers {
+		switch p.(type) {
+		case dataFieldParser, idFieldParser:
+			// Bound to d, tee has its own
+		default:
+			tee.parsers[name] = p
+		}
+	}
+	return tee
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("sse: parse error at line %d (field %q): %v", e.Line, e.FieldName, e.Cause)
+}
+
+// Unwrap returns the cause of the error.
+func (e *ParseError) Unwrap() error {
+	return e.Cause
+}
+
+// Err returns the error which stopped decoding, such as a network error or a line
+// longer than the buffer size (bufio.ErrTooLong), so that consumers of a channel
+// fed by Decode can tell it apart from the end of the input. It returns nil
+// while decoding can go on, and once the input ended cleanly with io.EOF.
+// In strict mode, a *ParseError is returned by Decode rather than by Err:
+// decoding resumes with the next line after a malformed one, so Err stays nil
+// and consumers stopping when Err is set do not stop on a recoverable error.
+// Err must not be called concurrently with Decode.
+func (d *Decoder) Err() error {
+	return d.err
+}
+
 // Retry returns the amount of milliseconds to wait before attempting to reconnect to the event source.
 func (d *Decoder) Retry() int {
-	return d.retry
+	return d.partial.retry
 }
 
-// Decode reads the input stream and parses events from it. Any error while reading is  returned.
+// serverRetry returns the retry time sent by the server, if any.
+func (d *Decoder) serverRetry() (time.Duration, bool) {
+	return time.Duration(d.partial.retry) * time.Millisecond, d.partial.retrySet
+}
+
+// Stats returns a snapshot of the decoder counters.
+// It is safe to call Stats while another goroutine is decoding.
+func (d *Decoder) Stats() DecoderStats {
+	return DecoderStats{
+		DuplicatesDropped:      atomic.LoadUint64(&d.stats.DuplicatesDropped),
+		DroppedByRateLimiter:   atomic.LoadUint64(&d.stats.DroppedByRateLimiter),
+		DroppedByDataLineLimit: atomic.LoadUint64(&d.stats.DroppedByDataLineLimit),
+	}
+}
+
+// Decode reads the input stream and parses events from it. Any error while reading is  returned
+// wrapped in an *SSEError, io.EOF is returned as is once the input stream ends.
+// In strict mode, malformed lines are reported with a *ParseError; decoding can be resumed
+// with the next line by calling Decode again. When the malformed line is a field of an
+// event, the rest of that event is skipped.
+// Decode is already pull-based: it returns one event per call, without any goroutine,
+// hence Decoder has no Next method unlike the channel-based BinaryDecoder and FileDecoder.
 func (d *Decoder) Decode() (*MessageEvent, error) {
 	// Stores event data, which is filled after one or many lines from the reader
-	var name string
-	var eventSeen bool
+	ev := &d.partial
+	ev.reset()
 
 	scanner := d.scanner
-	data := d.data
-	data.Reset()
+	// discard drops the current event and skips lines until the next empty one
+	discard := func(err error) {
+		d.onError(err)
+		ev.reset()
+		d.skipping = true
+	}
 	for scanner.Scan() {
 		line := scanner.Text()
+		d.line++
+		if err := d.lineErr; err != nil {
+			// Only set in recovery mode, see recoveringSplit
+			d.lineErr = nil
+			discard(protocolError(err))
+			continue
+		}
+		if d.strict && d.lineEnding != LineEndingAuto && strings.ContainsAny(line, "\r\n") {
+			err := d.parseError(line, ErrUnexpectedLineEnding)
+			if d.onError == nil {
+				return nil, err
+			}
+			discard(err)
+			continue
+		}
+		if d.skipping {
+			d.skipping = len(line) != 0
+			continue
+		}
+
 		// Empty line? => Dispatch event
 		if len(line) == 0 {
-			if eventSeen {
-				// Trim the last LF
-				if l := data.Len(); l > 0 {
-					data.Truncate(l - 1)
-				}
+			if ev.seen {
 				// Note the event source spec as defined by w3.org requires
 				// skips the event dispatching if the event name collides with
 				// the name of any event as defined in the DOM Events spec.
 				// Decoder does not perform this check, hence it could yield
 				// events that would not be valid in a browser.
-				return &MessageEvent{d.lastEventID, name, data.String()}, nil
+				msg := ev.event()
+				if d.accept(msg, ev.idSeen) {
+					return msg, nil
+				}
+				// Event dropped, keep scanning for the next one
+				ev.reset()
 			}
 			continue
 		}
@@ -76,26 +360,32 @@ func (d *Decoder) Decode() (*MessageEvent, error) {
 			}
 		}
 
-		switch fieldName {
-		case "event":
-			name = value
-			eventSeen = true
-		case "data":
-			data.WriteString(value)
-			data.WriteByte('\n')
-			eventSeen = true
-		case "id":
-			d.lastEventID = value
-			eventSeen = true
-		case "retry":
-			retry, err := strconv.Atoi(value)
-			if err == nil && retry >= 0 {
-				d.retry = retry
+		parser, ok := d.parsers[fieldName]
+		if !ok {
+			// Ignore field
+			continue
+		}
+		if err := parser.Parse(value, ev); errors.Is(err, ErrSkipEvent) {
+			ev.reset()
+			d.skipping = true
+		} else if err != nil {
+			err = d.parseError(line, err)
+			if d.onError == nil {
+				// The next call resumes after the event
+				d.skipping = true
+				return nil, err
 			}
+			discard(err)
+		}
+	}
 
-		default:
-			// Ignore field
+	if err := scanner.Err(); err != nil {
+		if err == bufio.ErrTooLong {
+			d.err = protocolError(err)
+		} else {
+			d.err = &SSEError{Code: ErrCodeNetwork, Message: "cannot read stream", Cause: err}
 		}
+		return nil, d.err
 	}
 
 	// From the specification:
@@ -104,3 +394,39 @@ func (d *Decoder) Decode() (*MessageEvent, error) {
 	//  empty line, the incomplete event is not dispatched.)"
 	return nil, io.EOF
 }
+
+// parseError reports a malformed line at the current position of the decoder.
+func (d *Decoder) parseError(line string, cause error) error {
+	fieldName := line
+	if colonIndex := strings.IndexByte(line, ':'); colonIndex >= 0 {
+		fieldName = line[:colonIndex]
+	}
+	return protocolError(&ParseError{Line: d.line, Content: line, FieldName: fieldName, Cause: cause})
+}
+
+func protocolError(err error) error {
+	return &SSEError{Code: ErrCodeProtocol, Message: "malformed stream", Cause: err}
+}
+
+// accept tells whether a fully parsed event must be dispatched.
+// idSeen is set when the event block contained an id field.
+func (d *Decoder) accept(ev *MessageEvent, idSeen bool) bool {
+	if d.dedup != nil && idSeen && ev.LastEventID != "" && !d.dedup.add(ev.LastEventID) {
+		atomic.AddUint64(&d.stats.DuplicatesDropped, 1)
+		return false
+	}
+	if d.window != nil && !d.window.add(ev, d.now()) {
+		atomic.AddUint64(&d.stats.DuplicatesDropped, 1)
+		return false
+	}
+	for _, filter := range d.filters {
+		if !filter(ev) {
+			return false
+		}
+	}
+	if d.limiter != nil && !d.limiter.Allow() {
+		atomic.AddUint64(&d.stats.DroppedByRateLimiter, 1)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package sse
+
+import (
+	"context"
+	"io"
+)
+
+// DecodeContext is like Decode, but returns as soon as ctx is done, even when
+// the input blocks and does not unblock on Close. The error then wraps the
+// error of ctx, and the decoder must no longer be used: the read in progress
+// is abandoned, it completes in the background and the data it reads is lost.
+// Unless ctx can never be done, reads are made by a goroutine started for the
+// duration of the call.
+func (d *Decoder) DecodeContext(ctx context.Context) (*MessageEvent, error) {
+	d.reader.start(ctx)
+	defer d.reader.stop()
+	return d.Decode()
+}
+
+// contextReader reads from r, and gives up waiting for a read once ctx is done.
+type contextReader struct {
+	r   io.Reader
+	ctx context.Context
+	// Set while reads are made by the goroutine of start
+	reads   chan []byte
+	results chan readResult
+	// Reused by the reads made by the goroutine, which may still write to it
+	// once abandoned
+	buf []byte
+}
+
+type readResult struct {
+	n   int
+	err error
+}
+
+// start makes the reads in a goroutine until stop is called, so that they can
+// be abandoned once ctx is done.
+func (c *contextReader) start(ctx context.Context) {
+	c.ctx = ctx
+	if ctx.Done() == nil {
+		return
+	}
+	c.reads = make(chan []byte)
+	c.results = make(chan readResult, 1)
+	go func(reads <-chan []byte, results chan<- readResult) {
+		for p := range reads {
+			n, err := c.r.Read(p)
+			results <- readResult{n, err}
+		}
+	}(c.reads, c.results)
+}
+
+func (c *contextReader) stop() {
+	if c.reads != nil {
+		close(c.reads)
+		c.reads = nil
+	}
+	c.ctx = nil
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if c.reads == nil {
+		return c.r.Read(p)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if cap(c.buf) < len(p) {
+		c.buf = make([]byte, len(p))
+	}
+	buf := c.buf[:len(p)]
+	c.reads <- buf
+	select {
+	case res := <-c.results:
+		return copy(p, buf[:res.n]), res.err
+	case <-c.ctx.Done():
+		return 0, c.ctx.Err()
+	}
+}
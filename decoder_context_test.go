@@ -0,0 +1,62 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeContext(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	go w.Write([]byte("data: first\n\n"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	d := NewDecoder(r)
+	ev, err := d.DecodeContext(ctx)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "first", ev.Data)
+	}
+
+	// The pipe blocks until written to
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := d.DecodeContext(ctx)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), "unexpected error %v", err)
+		assert.True(t, hasErrorCode(err, ErrCodeNetwork))
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.FailNow(t, "decoding not canceled")
+	}
+}
+
+func TestDecodeContextStopsReading(t *testing.T) {
+	in := strings.Repeat("data: event\n\n", 100)
+	d := NewDecoderWithOptions(strings.NewReader(in), WithBufferSize(16))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		ev, err := d.DecodeContext(ctx)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "event", ev.Data)
+	}
+	// The reading goroutine of every call is gone
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.True(t, runtime.NumGoroutine() <= before, "reading goroutines left running")
+}
@@ -0,0 +1,41 @@
+//go:build go1.23
+
+package sse
+
+import (
+	"context"
+	"io"
+	"iter"
+)
+
+// Events returns an iterator over the decoded events:
+//
+//	for ev, err := range d.Events(ctx) {
+//		if err != nil {
+//			// handle err
+//		}
+//	}
+//
+// Iteration stops at the end of the input, or after yielding an error which
+// stops decoding, see Err. Errors which do not stop decoding, such as a
+// *ParseError in strict mode, are yielded with a nil event and iteration goes on.
+// ctx is checked before decoding every event, its error is yielded once done.
+// No goroutine is started, hence a blocked read is only interrupted by closing
+// the input; see DecodeContext for inputs which do not unblock on Close.
+func (d *Decoder) Events(ctx context.Context) iter.Seq2[*MessageEvent, error] {
+	return func(yield func(*MessageEvent, error) bool) {
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			ev, err := d.Decode()
+			if err == io.EOF {
+				return
+			}
+			if !yield(ev, err) || d.Err() != nil {
+				return
+			}
+		}
+	}
+}
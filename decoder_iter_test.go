@@ -0,0 +1,73 @@
+//go:build go1.23
+
+package sse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoderEvents(t *testing.T) {
+	in := "data: first\n\nevent: a\r\n\nid: 2\ndata: second\n\n"
+	d := NewDecoderWithOptions(strings.NewReader(in), WithLineEnding(LineEndingLFOnly), WithStrictMode())
+
+	var data []string
+	var errs []error
+	for ev, err := range d.Events(context.Background()) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		data = append(data, ev.Data)
+	}
+	assert.Equal(t, []string{"first", "second"}, data)
+	if assert.Len(t, errs, 1) {
+		var parseErr *ParseError
+		assert.True(t, errors.As(errs[0], &parseErr))
+	}
+	assert.NoError(t, d.Err())
+
+	// Breaking out of the loop stops decoding
+	d = newDecoder(strings.Repeat("data: event\n\n", 3))
+	count := 0
+	for range d.Events(context.Background()) {
+		count++
+		break
+	}
+	assert.Equal(t, 1, count)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	errs = nil
+	for _, err := range newDecoder("data: first\n\n").Events(ctx) {
+		errs = append(errs, err)
+	}
+	if assert.Len(t, errs, 1) {
+		assert.True(t, errors.Is(errs[0], context.Canceled))
+	}
+
+	// The context is checked between events, without reading further
+	r, w := io.Pipe()
+	defer w.Close()
+	go w.Write([]byte("data: first\n\n"))
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	data, errs = nil, nil
+	for ev, err := range NewDecoder(r).Events(ctx) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		data = append(data, ev.Data)
+		cancel()
+	}
+	assert.Equal(t, []string{"first"}, data)
+	if assert.Len(t, errs, 1) {
+		assert.True(t, errors.Is(errs[0], context.Canceled))
+	}
+}
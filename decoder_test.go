@@ -1,9 +1,13 @@
 package sse
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
 	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -13,6 +17,7 @@ func TestEOFIsReturned(t *testing.T) {
 	ev, err := decoder.Decode()
 	assert.Equal(t, io.EOF, err)
 	assert.Nil(t, ev)
+	assert.NoError(t, decoder.Err())
 }
 
 func TestBigEventGrowsTheBuffer(t *testing.T) {
@@ -176,6 +181,244 @@ func TestDecodeRetry(t *testing.T) {
 	assert.Equal(t, io.EOF, err)
 }
 
+func TestDecodeReturnsReadErrors(t *testing.T) {
+	decoder := NewDecoderSize(bytes.NewReader([]byte("data: "+strings.Repeat("e", 64)+"\n\n")), 32)
+	_, err := decoder.Decode()
+	assert.True(t, errors.Is(err, bufio.ErrTooLong))
+	assert.True(t, hasErrorCode(err, ErrCodeProtocol))
+	assert.Equal(t, err, decoder.Err())
+
+	decoder = NewDecoder(iotest.TimeoutReader(strings.NewReader("data: first\n\ndata: partial")))
+	_, err = decoder.Decode()
+	assert.NoError(t, err)
+	assert.NoError(t, decoder.Err())
+	_, err = decoder.Decode()
+	assert.True(t, errors.Is(decoder.Err(), iotest.ErrTimeout))
+	assert.True(t, hasErrorCode(decoder.Err(), ErrCodeNetwork))
+	assert.Equal(t, err, decoder.Err())
+}
+
+func TestDecoderWithBufferSize(t *testing.T) {
+	in := "data: " + strings.Repeat("e", 64) + "\n\n"
+	_, err := NewDecoderWithOptions(bytes.NewReader([]byte(in)), WithBufferSize(32)).Decode()
+	assert.True(t, errors.Is(err, bufio.ErrTooLong))
+
+	ev, err := NewDecoderWithOptions(bytes.NewReader([]byte(in)), WithBufferSize(128)).Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, strings.Repeat("e", 64), ev.Data)
+	}
+}
+
+func TestDecoderWithSplitFunc(t *testing.T) {
+	// Lines separated by NUL bytes
+	split := func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.IndexByte(data, 0); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+	decoder := NewDecoderWithOptions(bytes.NewReader([]byte("event: a\x00data: b\nc\x00\x00")), WithSplitFunc(split))
+	ev, err := decoder.Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, &MessageEvent{Name: "a", Data: "b\nc"}, ev)
+	}
+}
+
+func TestDecoderErrorRecovery(t *testing.T) {
+	long := strings.Repeat("e", 3*bufio.MaxScanTokenSize)
+	in := "data: before\n\ndata: partial\ndata: " + long + "\ndata: discarded\n\ndata: after\n\n"
+
+	var errs []error
+	decoder := NewDecoderWithOptions(bytes.NewReader([]byte(in)), WithErrorRecovery(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	var data []string
+	for {
+		ev, err := decoder.Decode()
+		if err != nil {
+			assert.Equal(t, io.EOF, err)
+			break
+		}
+		data = append(data, ev.Data)
+	}
+
+	assert.Equal(t, []string{"before", "after"}, data)
+	if assert.Len(t, errs, 1) {
+		assert.True(t, errors.Is(errs[0], bufio.ErrTooLong))
+	}
+}
+
+func TestDecoderErrorRecoveryWithStrictMode(t *testing.T) {
+	var errs []error
+	decoder := NewDecoderWithOptions(
+		bytes.NewReader([]byte("data: first\n\ndata: a\r\nid: 1\n\ndata: last\n\n")),
+		WithLineEnding(LineEndingLFOnly), WithStrictMode(),
+		WithErrorRecovery(func(err error) { errs = append(errs, err) }),
+	)
+
+	ev, err := decoder.Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "first", ev.Data)
+	}
+	ev, err = decoder.Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "last", ev.Data)
+		assert.Equal(t, "", ev.LastEventID)
+	}
+	if assert.Len(t, errs, 1) {
+		var parseErr *ParseError
+		assert.True(t, errors.As(errs[0], &parseErr))
+	}
+}
+
+func TestLineEndingModes(t *testing.T) {
+	for _, test := range []struct {
+		mode     LineEndingMode
+		in       string
+		expected string
+	}{
+		{LineEndingAuto, "data: a\rdata: b\r\ndata: c\n\n", "a\nb\nc"},
+		{LineEndingLFOnly, "data: a\r\ndata: b\n\n", "a\r\nb"},
+		{LineEndingLFOnly, "data: a\rdata: b\n\n", "a\rdata: b"},
+		{LineEndingCRLFOnly, "data: a\r\ndata: b\ndata: c\r\n\r\n", "a\nb\ndata: c"},
+		{LineEndingCROnly, "data: a\rdata: b\n\r\r", "a\nb\n"},
+	} {
+		decoder := NewDecoderWithOptions(bytes.NewReader([]byte(test.in)), WithLineEnding(test.mode))
+		ev, err := decoder.Decode()
+		if assert.NoError(t, err, "mode %d, in: %q", test.mode, test.in) {
+			assert.Equal(t, test.expected, ev.Data, "mode %d, in: %q", test.mode, test.in)
+		}
+	}
+}
+
+func TestLineEndingModesStrict(t *testing.T) {
+	for _, test := range []struct {
+		mode LineEndingMode
+		in   string
+	}{
+		{LineEndingLFOnly, "data: a\r\n\n"},
+		{LineEndingLFOnly, "data: a\rdata: b\n\n"},
+		{LineEndingCRLFOnly, "data: a\ndata: b\r\n\r\n"},
+		{LineEndingCROnly, "data: a\r\n\r"},
+	} {
+		decoder := NewDecoderWithOptions(bytes.NewReader([]byte(test.in)), WithLineEnding(test.mode), WithStrictMode())
+		_, err := decoder.Decode()
+		var parseErr *ParseError
+		if assert.True(t, errors.As(err, &parseErr), "mode %d, in: %q", test.mode, test.in) {
+			assert.Equal(t, ErrUnexpectedLineEnding, parseErr.Cause)
+		}
+	}
+}
+
+func TestParseErrorReportsLineAndField(t *testing.T) {
+	decoder := NewDecoderWithOptions(
+		bytes.NewReader([]byte("data: first\n\nid: 2\nevent: a\r\n\n")),
+		WithLineEnding(LineEndingLFOnly), WithStrictMode(),
+	)
+	_, err := decoder.Decode()
+	assert.NoError(t, err)
+
+	_, err = decoder.Decode()
+	var parseErr *ParseError
+	if assert.True(t, errors.As(err, &parseErr)) {
+		assert.Equal(t, 4, parseErr.Line)
+		assert.Equal(t, "event", parseErr.FieldName)
+		assert.Equal(t, "event: a\r", parseErr.Content)
+		assert.Equal(t, `sse: parse error at line 4 (field "event"): decoder: unexpected line ending`, parseErr.Error())
+		assert.True(t, errors.Is(err, ErrUnexpectedLineEnding))
+	}
+}
+
+func TestLineEndingAutoStrictAcceptsAllTerminators(t *testing.T) {
+	decoder := NewDecoderWithOptions(bytes.NewReader([]byte("data: a\rdata: b\r\ndata: c\n\n")), WithStrictMode())
+	ev, err := decoder.Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "a\nb\nc", ev.Data)
+	}
+}
+
+func TestDecoderWithFilters(t *testing.T) {
+	var filtered []*MessageEvent
+	decoder := NewDecoderWithOptions(
+		bytes.NewReader([]byte(": comment\n\nretry: 10\n\nid: 1\nevent: a\ndata: 1\n\nevent: b\ndata: 2\n\nevent: a\ndata: 3\n\n")),
+		WithFilter(func(ev *MessageEvent) bool {
+			filtered = append(filtered, ev)
+			return ev.Name == "a"
+		}),
+		WithFilter(func(ev *MessageEvent) bool { return ev.Data != "1" }),
+	)
+
+	ev, err := decoder.Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "3", ev.Data)
+		assert.Equal(t, "1", ev.LastEventID)
+	}
+	_, err = decoder.Decode()
+	assert.Equal(t, io.EOF, err)
+
+	// Comment and retry only blocks never reach the filters
+	if assert.Len(t, filtered, 3) {
+		assert.Equal(t, &MessageEvent{LastEventID: "1", Name: "a", Data: "1"}, filtered[0])
+	}
+}
+
+func TestDecoderWithRateLimit(t *testing.T) {
+	decoder := NewDecoderWithOptions(
+		bytes.NewReader([]byte(strings.Repeat("data: event\n\n", 10))),
+		WithRateLimit(2),
+	)
+
+	dispatched := 0
+	for {
+		if _, err := decoder.Decode(); err != nil {
+			assert.Equal(t, io.EOF, err)
+			break
+		}
+		dispatched++
+	}
+	// The burst allows the first two events only
+	assert.Equal(t, 2, dispatched)
+	assert.Equal(t, uint64(8), decoder.Stats().DroppedByRateLimiter)
+}
+
+func TestDecoderWithMaxDataLines(t *testing.T) {
+	in := strings.Repeat("data: x\n", 10001) + "\n" + strings.Repeat("data: y\n", 10000) + "\n"
+	decoder := NewDecoderWithOptions(bytes.NewReader([]byte(in)), WithMaxDataLines(10000))
+
+	ev, err := decoder.Decode()
+	if assert.NoError(t, err) {
+		assert.True(t, strings.HasPrefix(ev.Data, "y\n"))
+	}
+	_, err = decoder.Decode()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, uint64(1), decoder.Stats().DroppedByDataLineLimit)
+}
+
+func TestDecoderWithMaxDataLinesStrict(t *testing.T) {
+	decoder := NewDecoderWithOptions(bytes.NewReader([]byte("data: a\ndata: b\ndata: c\n\n")), WithMaxDataLines(2), WithStrictMode())
+
+	_, err := decoder.Decode()
+	var parseErr *ParseError
+	if assert.True(t, errors.As(err, &parseErr)) {
+		assert.Equal(t, 3, parseErr.Line)
+		assert.Equal(t, "data", parseErr.FieldName)
+		assert.True(t, errors.Is(err, ErrTooManyDataLines))
+	}
+
+	// The rest of the rejected event is skipped
+	decoder = NewDecoderWithOptions(bytes.NewReader([]byte("data: x\ndata: x\ndata: x\ndata: x\ndata: x\n\ndata: next\n\n")), WithMaxDataLines(2), WithStrictMode())
+	_, err = decoder.Decode()
+	assert.True(t, errors.Is(err, ErrTooManyDataLines))
+	ev, err := decoder.Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "next", ev.Data)
+	}
+}
+
 func BenchmarkDecodeEmptyEvent(b *testing.B) {
 	runDecodingBenchmark(b, "data: \n\n")
 }
@@ -208,6 +451,44 @@ func BenchmarkDecode16kEvent(b *testing.B) {
 	runDecodingBenchmark(b, messageEventToString(ev))
 }
 
+func BenchmarkDecodeFilteredEvents(b *testing.B) {
+	in := []byte(strings.Repeat("event: ignored\ndata: filtered event\n\n", 100) + "event: kept\ndata: kept event\n\n")
+	keep := WithFilter(func(ev *MessageEvent) bool {
+		return ev.Name == "kept"
+	})
+	dispatched := 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		decoder := NewDecoderWithOptions(bytes.NewReader(in), keep)
+		for {
+			if _, err := decoder.Decode(); err != nil {
+				break
+			}
+			dispatched++
+		}
+	}
+	// Only the kept event must be dispatched, once per stream
+	b.ReportMetric(float64(dispatched)/float64(b.N), "dispatched/op")
+}
+
+// Decoding must not wait for the limiter: excess events are dropped.
+func BenchmarkDecodeRateLimitedEvents(b *testing.B) {
+	in := []byte(strings.Repeat("data: event\n\n", 1000))
+	limit := WithRateLimit(1)
+	var dropped uint64
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		decoder := NewDecoderWithOptions(bytes.NewReader(in), limit)
+		for {
+			if _, err := decoder.Decode(); err != nil {
+				break
+			}
+		}
+		dropped += decoder.Stats().DroppedByRateLimiter
+	}
+	b.ReportMetric(float64(dropped)/float64(b.N), "dropped/op")
+}
+
 func newDecoder(data string) *Decoder {
 	reader := bytes.NewReader([]byte(data))
 	return NewDecoder(reader)
@@ -222,3 +503,20 @@ func runDecodingBenchmark(b *testing.B, data string) {
 		reader.Seek(0, 0)
 	}
 }
+
+func TestDecoderTee(t *testing.T) {
+	in := ": comment\n\nevent: a\ndata: 1\n\nevent: b\ndata: 2\n\n"
+	decoder := NewDecoderWithOptions(strings.NewReader(in), WithFilter(func(ev *MessageEvent) bool {
+		return ev.Name == "b"
+	}))
+	var raw bytes.Buffer
+	decoder = decoder.Tee(&raw)
+
+	ev, err := decoder.Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "2", ev.Data)
+	}
+	_, err = decoder.Decode()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, in, raw.String())
+}
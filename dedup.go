@@ -0,0 +1,91 @@
+package sse
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"io"
+	"time"
+)
+
+// idWindow is a fixed size ring buffer of the most recently seen event IDs.
+type idWindow struct {
+	ids  []string
+	next int
+	seen map[string]struct{}
+}
+
+func newIDWindow(size int) *idWindow {
+	return &idWindow{
+		ids:  make([]string, 0, size),
+		seen: make(map[string]struct{}, size),
+	}
+}
+
+// add records id in the window, evicting the oldest id if the window is full.
+// It returns false if id is already present.
+func (w *idWindow) add(id string) bool {
+	if _, ok := w.seen[id]; ok {
+		return false
+	}
+	if len(w.ids) < cap(w.ids) {
+		w.ids = append(w.ids, id)
+	} else {
+		delete(w.seen, w.ids[w.next])
+		w.ids[w.next] = id
+		w.next = (w.next + 1) % len(w.ids)
+	}
+	w.seen[id] = struct{}{}
+	return true
+}
+
+// eventWindow remembers the hashes of the events seen within a sliding time window.
+type eventWindow struct {
+	d       time.Duration
+	entries []eventWindowEntry // Oldest first
+	seen    map[uint64]struct{}
+}
+
+type eventWindowEntry struct {
+	hash uint64
+	at   time.Time
+}
+
+func newEventWindow(d time.Duration) *eventWindow {
+	return &eventWindow{d: d, seen: make(map[uint64]struct{})}
+}
+
+// add records ev as seen at now, forgetting the events seen before now - d.
+// It returns false if an identical event is still in the window; the window of
+// an event is not extended by its duplicates.
+func (w *eventWindow) add(ev *MessageEvent, now time.Time) bool {
+	expired := 0
+	for _, entry := range w.entries {
+		if now.Sub(entry.at) < w.d {
+			break
+		}
+		delete(w.seen, entry.hash)
+		expired++
+	}
+	w.entries = w.entries[expired:]
+
+	h := hashEvent(ev)
+	if _, ok := w.seen[h]; ok {
+		return false
+	}
+	w.seen[h] = struct{}{}
+	w.entries = append(w.entries, eventWindowEntry{hash: h, at: now})
+	return true
+}
+
+// hashEvent hashes the id, name and data of ev.
+func hashEvent(ev *MessageEvent) uint64 {
+	h := fnv.New64a()
+	var length [8]byte
+	for _, field := range []string{ev.LastEventID, ev.Name, ev.Data} {
+		// Length prefixed, so that fields cannot run into each other
+		binary.LittleEndian.PutUint64(length[:], uint64(len(field)))
+		h.Write(length[:])
+		io.WriteString(h, field)
+	}
+	return h.Sum64()
+}
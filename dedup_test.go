@@ -0,0 +1,84 @@
+package sse
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIDWindowEvictsOldestID(t *testing.T) {
+	w := newIDWindow(2)
+	assert.True(t, w.add("1"))
+	assert.True(t, w.add("2"))
+	assert.False(t, w.add("1"))
+	assert.True(t, w.add("3"))
+	assert.True(t, w.add("1")) // "1" was evicted by "3"
+	assert.False(t, w.add("3"))
+}
+
+func TestDecoderDeduplication(t *testing.T) {
+	in := "id: 1\ndata: a\n\nid: 1\ndata: a\n\ndata: b\n\ndata: b\n\nid: 2\ndata: c\n\nid: 1\ndata: a\n\n"
+	decoder := NewDecoderWithOptions(bytes.NewReader([]byte(in)), WithDeduplication(8))
+
+	var data []string
+	for {
+		ev, err := decoder.Decode()
+		if err != nil {
+			assert.Equal(t, io.EOF, err)
+			break
+		}
+		data = append(data, ev.Data)
+	}
+
+	// Events without an id field are never deduplicated
+	assert.Equal(t, []string{"a", "b", "b", "c"}, data)
+	assert.Equal(t, uint64(2), decoder.Stats().DuplicatesDropped)
+}
+
+func TestDecoderDeduplicationWindowSize(t *testing.T) {
+	in := "id: 1\ndata: a\n\nid: 2\ndata: b\n\nid: 1\ndata: a\n\n"
+	decoder := NewDecoderWithOptions(bytes.NewReader([]byte(in)), WithDeduplication(1))
+
+	count := 0
+	for {
+		if _, err := decoder.Decode(); err != nil {
+			break
+		}
+		count++
+	}
+	assert.Equal(t, 3, count)
+	assert.Equal(t, uint64(0), decoder.Stats().DuplicatesDropped)
+}
+
+func TestEventWindowExpires(t *testing.T) {
+	w := newEventWindow(time.Second)
+	start := time.Now()
+	ev := &MessageEvent{Data: "a"}
+	assert.True(t, w.add(ev, start))
+	assert.False(t, w.add(&MessageEvent{Data: "a"}, start.Add(500*time.Millisecond)))
+	assert.True(t, w.add(&MessageEvent{Name: "a"}, start.Add(500*time.Millisecond)))
+	assert.True(t, w.add(ev, start.Add(time.Second)))
+	assert.Len(t, w.entries, 2)
+}
+
+func TestDecoderWithEventWindow(t *testing.T) {
+	in := "data: a\n\ndata: a\n\nevent: x\ndata: a\n\ndata: a\n\n"
+	decoder := NewDecoderWithOptions(bytes.NewReader([]byte(in)), WithEventWindow(time.Minute))
+	now := time.Now()
+	decoder.now = func() time.Time { return now }
+
+	ev, _ := decoder.Decode()
+	assert.Equal(t, &MessageEvent{Data: "a"}, ev)
+	// The duplicate is dropped
+	ev, _ = decoder.Decode()
+	assert.Equal(t, &MessageEvent{Name: "x", Data: "a"}, ev)
+
+	// Out of the window
+	now = now.Add(time.Minute)
+	ev, _ = decoder.Decode()
+	assert.Equal(t, &MessageEvent{Data: "a"}, ev)
+	assert.Equal(t, uint64(1), decoder.Stats().DuplicatesDropped)
+}
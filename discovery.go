@@ -0,0 +1,120 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default time a URL resolved by NewEventSourceFromService is used before being resolved again.
+const defaultServiceTTL = time.Minute
+
+// Timeout of the lookups resolving a service again on reconnection.
+const serviceLookupTimeout = 10 * time.Second
+
+var (
+	// ErrNoService error indicates no SRV record was found for a service.
+	ErrNoService = errors.New("eventsource: no SRV record found for the service")
+
+	// lookupSRV is replaced in tests
+	lookupSRV = net.DefaultResolver.LookupSRV
+)
+
+// serviceResolver resolves the URL of an event source from SRV records.
+type serviceResolver struct {
+	service, domain string
+	scheme, path    string
+	ttl             time.Duration
+	resolvedAt      time.Time
+}
+
+// WithServiceURL sets the scheme and path of the URLs built by NewEventSourceFromService.
+// They default to http and /.
+func WithServiceURL(scheme, path string) EventSourceOption {
+	return func(es *EventSource) {
+		es.serviceScheme = scheme
+		es.servicePath = path
+	}
+}
+
+// WithServiceTTL sets the time a URL resolved by NewEventSourceFromService is used before
+// it is resolved again on reconnection. It defaults to one minute.
+func WithServiceTTL(ttl time.Duration) EventSourceOption {
+	return func(es *EventSource) {
+		es.serviceTTL = ttl
+	}
+}
+
+// NewEventSourceFromService discovers the URL of the stream from the SRV records of
+// _service._tcp.domain, as used by DNS-SD, then connects like NewEventSourceWithContext:
+// ctx bounds the lookup, then the event source is closed once ctx is done.
+// The target with the highest priority is used.
+// On reconnection, the service is resolved again if the TTL set with WithServiceTTL
+// expired or the server could not be reached.
+func NewEventSourceFromService(ctx context.Context, service, domain string, opts ...EventSourceOption) (*EventSource, error) {
+	es := New("", opts...)
+	r := &serviceResolver{
+		service: service,
+		domain:  domain,
+		scheme:  es.serviceScheme,
+		path:    es.servicePath,
+		ttl:     es.serviceTTL,
+	}
+	if r.scheme == "" {
+		r.scheme = "http"
+	}
+	if r.ttl <= 0 {
+		r.ttl = defaultServiceTTL
+	}
+	u, err := r.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	es.url = u
+	es.resolver = r
+	return es, es.Connect(ctx)
+}
+
+func (r *serviceResolver) resolve(ctx context.Context) (string, error) {
+	_, addrs, err := lookupSRV(ctx, r.service, "tcp", r.domain)
+	if err != nil {
+		return "", &SSEError{Code: ErrCodeNetwork, Message: "cannot resolve service", Cause: err}
+	}
+	if len(addrs) == 0 {
+		return "", ErrNoService
+	}
+	r.resolvedAt = time.Now()
+	u := url.URL{
+		Scheme: r.scheme,
+		Host:   net.JoinHostPort(strings.TrimSuffix(addrs[0].Target, "."), strconv.Itoa(int(addrs[0].Port))),
+		Path:   r.path,
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u.String(), nil
+}
+
+// refreshServiceURL resolves the service again before a reconnection attempt when
+// needed, lastErr being the error of the previous attempt if any.
+// Failing lookups are logged and the current URL is kept.
+func (es *EventSource) refreshServiceURL(lastErr error) {
+	r := es.resolver
+	if r == nil || (time.Since(r.resolvedAt) < r.ttl && !hasErrorCode(lastErr, ErrCodeNetwork)) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(es.ctx, serviceLookupTimeout)
+	defer cancel()
+	u, err := r.resolve(ctx)
+	if err != nil {
+		es.logf("eventsource: cannot resolve service %s: %v", r.service, err)
+		return
+	}
+	es.stateMutex.Lock()
+	es.url = u
+	es.stateMutex.Unlock()
+}
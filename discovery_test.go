@@ -0,0 +1,135 @@
+package sse
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourceFromServiceResolvesAgainWhenUnreachable(t *testing.T) {
+	first := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("retry: 1\ndata: first\n\n"))
+	}))
+	second := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/events", req.URL.Path)
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("data: second\n\n"))
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer second.Close()
+
+	var mu sync.Mutex
+	target := first.URL
+	defer stubLookupSRV(t, func(service, domain string) string {
+		assert.Equal(t, "sse", service)
+		assert.Equal(t, "local.", domain)
+		mu.Lock()
+		defer mu.Unlock()
+		return target
+	})()
+
+	es, err := NewEventSourceFromService(context.Background(), "sse", "local.", WithServiceURL("http", "/events"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+	assert.Equal(t, first.URL+"/events", es.URL())
+	assert.Equal(t, "first", (<-es.MessageEvents()).Data)
+
+	// The first server moves away
+	first.Close()
+	mu.Lock()
+	target = second.URL
+	mu.Unlock()
+
+	select {
+	case ev := <-es.MessageEvents():
+		assert.Equal(t, "second", ev.Data)
+	case <-time.After(time.Second):
+		assert.FailNow(t, "the service was not resolved again")
+	}
+	assert.Equal(t, second.URL+"/events", es.URL())
+}
+
+func TestEventSourceFromServiceResolvesAgainOnceTTLExpired(t *testing.T) {
+	server, requests := newReconnectingServer(1)
+	defer server.Close()
+
+	var lookups int32
+	defer stubLookupSRV(t, func(service, domain string) string {
+		atomic.AddInt32(&lookups, 1)
+		return server.URL
+	})()
+
+	es, err := NewEventSourceFromService(context.Background(), "sse", "local.", WithServiceTTL(time.Nanosecond))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+	go discardMessageEvents(es)
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+
+	<-requests
+	<-requests
+	assert.Equal(t, int32(2), atomic.LoadInt32(&lookups))
+}
+
+func TestEventSourceFromServiceWithoutRecord(t *testing.T) {
+	defer stubLookupSRV(t, func(service, domain string) string { return "" })()
+	_, err := NewEventSourceFromService(context.Background(), "sse", "local.")
+	assert.Equal(t, ErrNoService, err)
+}
+
+func TestEventSourceFromServiceIsClosedWithContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+	defer stubLookupSRV(t, func(service, domain string) string { return server.URL })()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	es, err := NewEventSourceFromService(ctx, "sse", "local.")
+	if !assert.NoError(t, err) {
+		cancel()
+		return
+	}
+	go discardMessageEvents(es)
+	cancel()
+	assert.Equal(t, []ReadyState{Connecting, Open, Closing, Closed}, collectStates(es.ReadyState()))
+}
+
+// stubLookupSRV makes SRV lookups resolve to the host and port of the URL returned
+// by resolve, or to no record if it is empty. The returned function restores lookupSRV.
+func stubLookupSRV(t *testing.T, resolve func(service, domain string) string) func() {
+	original := lookupSRV
+	lookupSRV = func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		target := resolve(service, name)
+		if target == "" {
+			return "", nil, nil
+		}
+		u, _ := url.Parse(target)
+		port, _ := strconv.Atoi(u.Port())
+		return "", []*net.SRV{{Target: u.Hostname() + ".", Port: uint16(port)}}, nil
+	}
+	return func() { lookupSRV = original }
+}
@@ -2,8 +2,15 @@ package sse
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
+)
+
+var (
+	// ErrTransactionDone error indicates the transaction was already committed or rolled back.
+	ErrTransactionDone = errors.New("encoder: transaction already committed or rolled back")
 )
 
 type Encoder struct {
@@ -12,6 +19,13 @@ type Encoder struct {
 	out         io.Writer
 }
 
+// Transaction buffers events in memory until they are written at once by Commit.
+type Transaction struct {
+	e    *Encoder
+	buf  *bytes.Buffer
+	done bool
+}
+
 func NewEncoder(out io.Writer) *Encoder {
 	return &Encoder{
 		buf: new(bytes.Buffer),
@@ -21,26 +35,75 @@ func NewEncoder(out io.Writer) *Encoder {
 
 func (e *Encoder) Write(event *MessageEvent) (int, error) {
 	e.buf.Reset()
+	writeEvent(e.buf, event)
+	return e.out.Write(e.buf.Bytes())
+}
+
+func (e *Encoder) SetRetry(retryDelayInMillis int) {
+	e.buf.Reset()
+	e.buf.WriteString(fmt.Sprintf("retry: %d\n", retryDelayInMillis))
+	e.out.Write(e.buf.Bytes())
+}
+
+// Begin starts a transaction. Events written to the transaction are delivered
+// with a single write to the underlying writer once committed, so that clients
+// receive either all of them or none.
+func (e *Encoder) Begin() *Transaction {
+	return &Transaction{e: e, buf: new(bytes.Buffer)}
+}
 
+// WriteEvent adds an event to the transaction.
+func (tx *Transaction) WriteEvent(event *MessageEvent) error {
+	if tx.done {
+		return ErrTransactionDone
+	}
+	writeEvent(tx.buf, event)
+	return nil
+}
+
+// WriteComment adds a comment to the transaction. Multi-line text is written
+// as one comment line per line.
+func (tx *Transaction) WriteComment(text string) error {
+	if tx.done {
+		return ErrTransactionDone
+	}
+	for _, line := range strings.Split(text, "\n") {
+		tx.buf.WriteString(": " + line + "\n")
+	}
+	return nil
+}
+
+// Commit writes all the buffered events with a single write.
+func (tx *Transaction) Commit() error {
+	if tx.done {
+		return ErrTransactionDone
+	}
+	tx.done = true
+	if tx.buf.Len() == 0 {
+		return nil
+	}
+	_, err := tx.e.out.Write(tx.buf.Bytes())
+	return err
+}
+
+// Rollback discards the buffered events. It is a no-op once the transaction is done.
+func (tx *Transaction) Rollback() {
+	tx.done = true
+	tx.buf.Reset()
+}
+
+func writeEvent(buf *bytes.Buffer, event *MessageEvent) {
 	if event.LastEventID != "" {
-		e.buf.WriteString("id: " + event.LastEventID + "\n")
+		buf.WriteString("id: " + event.LastEventID + "\n")
 	}
 
 	if event.Name != "" {
-		e.buf.WriteString("name: " + event.Name + "\n")
+		buf.WriteString("name: " + event.Name + "\n")
 	}
 
 	if event.Data != "" {
-		e.buf.WriteString("data: " + event.Data + "\n")
+		buf.WriteString("data: " + event.Data + "\n")
 	}
 
-	e.buf.WriteString("\n")
-
-	return e.out.Write(e.buf.Bytes())
-}
-
-func (e *Encoder) SetRetry(retryDelayInMillis int) {
-	e.buf.Reset()
-	e.buf.WriteString(fmt.Sprintf("retry: %d\n", retryDelayInMillis))
-	e.out.Write(e.buf.Bytes())
+	buf.WriteString("\n")
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/go-rfc/sse/internal/testutils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -37,8 +38,71 @@ func TestEncoderSetRetry(t *testing.T) {
 	assert.Equal(t, "retry: 123\n", out.String())
 }
 
+func TestEncoderTransactionCommit(t *testing.T) {
+	out := &countingWriter{}
+	tx := NewEncoder(out).Begin()
+	assert.NoError(t, tx.WriteEvent(&MessageEvent{LastEventID: "1", Data: "snapshot"}))
+	assert.NoError(t, tx.WriteComment("version\nmarker"))
+	assert.NoError(t, tx.WriteEvent(&MessageEvent{LastEventID: "2", Data: "v2"}))
+	assert.Equal(t, 0, out.writes)
+
+	assert.NoError(t, tx.Commit())
+	assert.Equal(t, 1, out.writes)
+	assert.Equal(t, "id: 1\ndata: snapshot\n\n: version\n: marker\nid: 2\ndata: v2\n\n", out.String())
+
+	assert.Equal(t, ErrTransactionDone, tx.Commit())
+	assert.Equal(t, ErrTransactionDone, tx.WriteEvent(eventFull))
+	assert.Equal(t, 1, out.writes)
+}
+
+func TestEncoderTransactionRollback(t *testing.T) {
+	out := &countingWriter{}
+	tx := NewEncoder(out).Begin()
+	assert.NoError(t, tx.WriteEvent(eventFull))
+	tx.Rollback()
+
+	assert.Equal(t, ErrTransactionDone, tx.Commit())
+	assert.Equal(t, ErrTransactionDone, tx.WriteComment("too late"))
+	assert.Equal(t, 0, out.writes)
+}
+
+func TestEncoderTransactionIsReceivedAtomically(t *testing.T) {
+	runTest(t, func(handler *testutils.TestServerHandler) {
+		es, err := NewEventSource(handler.URL)
+		assert.Nil(t, err)
+
+		// Every write to the encoder output is sent and flushed on its own
+		out := &countingWriter{send: handler.Send}
+		tx := NewEncoder(out).Begin()
+		tx.WriteEvent(&MessageEvent{LastEventID: "1", Data: "snapshot"})
+		tx.WriteEvent(&MessageEvent{LastEventID: "2", Data: "v2"})
+		go tx.Commit()
+
+		first := <-es.MessageEvents()
+		second := <-es.MessageEvents()
+		assert.Equal(t, "snapshot", first.Data)
+		assert.Equal(t, "v2", second.Data)
+		assert.Equal(t, 1, out.writes)
+	})
+}
+
 func getEncoderAndOut() (*Encoder, *bytes.Buffer) {
 	out := new(bytes.Buffer)
 	e := NewEncoder(out)
 	return e, out
 }
+
+// countingWriter records the amount of calls to Write.
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+	send   func(string)
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if w.send != nil {
+		w.send(string(p))
+	}
+	return w.Buffer.Write(p)
+}
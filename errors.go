@@ -0,0 +1,87 @@
+package sse
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrorCode classifies the errors reported by the package.
+type ErrorCode int
+
+const (
+	// ErrCodeContentType indicates the content type of the stream is not allowed.
+	ErrCodeContentType ErrorCode = iota + 1
+	// ErrCodeHTTPStatus indicates the server answered with an unexpected HTTP status code.
+	ErrCodeHTTPStatus
+	// ErrCodeNetwork indicates the connection to the server failed or was interrupted.
+	ErrCodeNetwork
+	// ErrCodeRetryExhausted indicates the event source gave up reconnecting.
+	ErrCodeRetryExhausted
+	// ErrCodeProtocol indicates the stream does not follow the server-sent events format.
+	ErrCodeProtocol
+)
+
+// SSEError wraps the errors of EventSource and Decoder, so that they can be
+// handled programmatically using errors.As and switching on Code.
+type SSEError struct {
+	Code ErrorCode
+	// StatusCode of the HTTP response, if any.
+	StatusCode int
+	Message    string
+	Cause      error
+}
+
+func (e *SSEError) Error() string {
+	switch {
+	case e.Cause == nil:
+		return "sse: " + e.Message
+	case e.Message == "":
+		return "sse: " + e.Cause.Error()
+	default:
+		return "sse: " + e.Message + ": " + e.Cause.Error()
+	}
+}
+
+// Unwrap returns the cause of the error.
+func (e *SSEError) Unwrap() error {
+	return e.Cause
+}
+
+func (c ErrorCode) String() string {
+	switch c {
+	case ErrCodeContentType:
+		return "content type"
+	case ErrCodeHTTPStatus:
+		return "http status"
+	case ErrCodeNetwork:
+		return "network"
+	case ErrCodeRetryExhausted:
+		return "retry exhausted"
+	case ErrCodeProtocol:
+		return "protocol"
+	default:
+		return "unknown"
+	}
+}
+
+// HTTPStatusError reports a connection attempt answered with an unexpected
+// HTTP status code.
+type HTTPStatusError struct {
+	StatusCode int
+	Status     string
+	URL        string
+	// Reconnect tells whether the event source reconnects, see WithStatusPolicy.
+	Reconnect bool
+	// RetryAfter is the minimum delay before reconnecting, see WithStatusPolicy.
+	RetryAfter time.Duration
+}
+
+func (e *HTTPStatusError) Error() string {
+	return "eventsource: unexpected status " + e.Status + " from " + e.URL
+}
+
+// hasErrorCode tells whether err is an *SSEError with the given code.
+func hasErrorCode(err error, code ErrorCode) bool {
+	var sseErr *SSEError
+	return errors.As(err, &sseErr) && sseErr.Code == code
+}
@@ -0,0 +1,76 @@
+package sse
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSEErrorMessage(t *testing.T) {
+	cause := errors.New("cause")
+	for _, test := range []struct {
+		err      *SSEError
+		expected string
+	}{
+		{&SSEError{Message: "message"}, "sse: message"},
+		{&SSEError{Cause: cause}, "sse: cause"},
+		{&SSEError{Message: "message", Cause: cause}, "sse: message: cause"},
+	} {
+		assert.Equal(t, test.expected, test.err.Error())
+	}
+}
+
+func TestSSEErrorAs(t *testing.T) {
+	var err error = &SSEError{Code: ErrCodeContentType, Cause: ErrContentType}
+
+	var sseErr *SSEError
+	if assert.True(t, errors.As(err, &sseErr)) {
+		assert.Equal(t, ErrCodeContentType, sseErr.Code)
+	}
+	assert.True(t, errors.Is(err, ErrContentType))
+	assert.False(t, errors.Is(err, ErrStreamClosed))
+}
+
+func TestEventSourceNetworkError(t *testing.T) {
+	server := httptest.NewServer(nil)
+	server.Close()
+
+	es, err := NewEventSource(server.URL)
+	assert.True(t, hasErrorCode(err, ErrCodeNetwork))
+	assertStates(t, []ReadyState{Connecting, Closing, Closed}, es.ReadyState())
+}
+
+func TestEventSourceHTTPStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	_, err := NewEventSource(server.URL)
+	var sseErr *SSEError
+	if assert.True(t, errors.As(err, &sseErr)) {
+		assert.Equal(t, ErrCodeHTTPStatus, sseErr.Code)
+		assert.Equal(t, http.StatusInternalServerError, sseErr.StatusCode)
+	}
+}
+
+func TestEventSourceHTTPStatusErrorAs(t *testing.T) {
+	for _, statusCode := range []int{http.StatusForbidden, http.StatusServiceUnavailable, http.StatusTooManyRequests} {
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(statusCode)
+		}))
+
+		_, err := NewEventSource(server.URL)
+		var statusErr *HTTPStatusError
+		if assert.True(t, errors.As(err, &statusErr), "status %d", statusCode) {
+			assert.Equal(t, statusCode, statusErr.StatusCode)
+			assert.Equal(t, server.URL, statusErr.URL)
+			assert.Contains(t, statusErr.Error(), http.StatusText(statusCode))
+		}
+		server.Close()
+	}
+}
@@ -1,11 +1,19 @@
 package sse
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
+	"log"
+	"mime"
 	"net/http"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"golang.org/x/oauth2"
 )
 
 const (
@@ -15,38 +23,229 @@ const (
 var (
 	// ErrContentType error indicates the content-type header is not accepted
 	ErrContentType = errors.New("eventsource: the content type of the stream is not allowed")
+	// ErrStreamClosed error indicates the server closed the stream cleanly
+	ErrStreamClosed = errors.New("eventsource: the stream was closed by the server")
+	// ErrNoContent error indicates the server answered 204 No Content, telling the
+	// event source not to reconnect
+	ErrNoContent = errors.New("eventsource: the server asked not to reconnect")
+	// ErrAlreadyStarted error indicates Connect was called more than once
+	ErrAlreadyStarted = errors.New("eventsource: already connected")
+	// ErrClosed error indicates Connect was called on a closed event source
+	ErrClosed = errors.New("eventsource: closed")
 )
 
 type (
 	// EventSource connects and processes events from an HTTP server-sent events stream.
 	EventSource struct {
+		// Accessed atomically, kept first for 64-bit alignment
+		connectAttempts uint64
+		bytesReceived   uint64
+
+		id          string
+		ctx         context.Context
+		cancel      context.CancelFunc
 		url         string
 		lastEventID string
 		d           *Decoder
 		resp        *http.Response
 		closed      bool
 		closedMutex *sync.RWMutex
+		closing     chan struct{}
+		closingOnce *sync.Once
+		done        chan struct{}
+		err         error
 		out         chan *MessageEvent
+		overflow    OverflowPolicy
 		readyState  chan Status
+		errs        chan error
+		onError     func(error)
+		// Called on every ready state change, see WithStateChangeHandler
+		onStateChange func(from, to ReadyState)
+		subs          map[*subscription]struct{}
+		subsMutex     *sync.RWMutex
+		// Cancel functions of the listeners, guarded by subsMutex
+		listeners    map[ListenerID]func()
+		lastListener ListenerID
+		logger       *log.Logger
+		client       *http.Client
+		dialTimeout  time.Duration
+		backoffBase  time.Duration
+		backoffMax   time.Duration
+		strategy     ReconnectionStrategy
+		maxAttempts  int
+		retryBudget  time.Duration
+		// See circuit.go
+		circuitThreshold int
+		circuitCoolDown  time.Duration
+		metaEvents       bool
+		minRetry         time.Duration
+		maxRetry         time.Duration
+		advisory         string
+		connectTimeout   time.Duration
+		connectivity     Connectivity
+		connectDeadline  time.Time
+		watchdog         time.Duration
+		method           string
+		bodyFactory      func() (io.ReadCloser, string)
+		// Applied to every request, in order
+		decorators    []func(*http.Request)
+		sessionID     string
+		sessionHeader string
+		fingerprint   string
+		tokenSource   oauth2.TokenSource
+		failoverURLs  []string
+		idQueryParam  string
+		accept        string
+		contentTypes  []string
+		validator     func(*http.Response) error
+		statusPolicy  StatusPolicy
+		// See redirect.go
+		permanentRedirects bool
+		onReconnect        func(*http.Request, ReconnectInfo)
+		rewriter           func(*http.Request) error
+		onResume           func(lastEventID, firstEventID string)
+		// Set for a reconnection attempt, like connectDeadline, cleared by connectOnce
+		reconnectInfo *ReconnectInfo
+		omitIDHeader  bool
+		idStore       IDStore
+		idSaver       *idSaver
+		hmacKey       []byte
+		hmacAlgorithm string
+		leaseURL      string
+		leaseInterval time.Duration
+		leaseBody     func() io.Reader
+		resolver      *serviceResolver
+		serviceScheme string
+		servicePath   string
+		serviceTTL    time.Duration
+		wsURL         string
+		wsProtocol    string
+		wsActive      bool
+		cacheURL      string
+		cacheCapacity int
+		cacheTimeout  time.Duration
+
+		// Current ReadyState, accessed atomically
+		state atomic.Uint32
+		// Current retry time, see retry.go
+		retry atomic.Int64
+		// Time of the last data received, see watchdog.go
+		lastContact atomic.Int64
+		// Whether Connect was called
+		started atomic.Bool
+
+		// Guards the fields describing the current connection of the event source
+		// and its statistics
+		stateMutex          *sync.RWMutex
+		lastReconnect       time.Time
+		createdAt           time.Time
+		lastConnectedAt     time.Time
+		connectCount        uint64
+		disconnectCount     uint64
+		eventsReceived      uint64
+		eventsDropped       uint64
+		consecutiveFailures uint32
+		// URL given on creation and index of the current URL, see failover.go
+		primaryURL    string
+		failoverIndex int
+		origin        string
+		// Events whose delivery was interrupted by Close, see Drain
+		undelivered []*MessageEvent
+
+		// Closed by Resume while paused, see pause.go
+		resumed    chan struct{}
+		pauseMutex *sync.Mutex
+
+		healthAddr   string
+		healthPath   string
+		healthServer *http.Server
 	}
 )
 
 // NewEventSource connects and returns an EventSource.
-func NewEventSource(url string) (*EventSource, error) {
+func NewEventSource(url string, opts ...EventSourceOption) (*EventSource, error) {
+	return NewEventSourceWithContext(context.Background(), url, opts...)
+}
+
+// NewEventSourceWithContext connects and returns an EventSource which is closed
+// when ctx is done, with the error of ctx as ReadyState error.
+func NewEventSourceWithContext(ctx context.Context, url string, opts ...EventSourceOption) (*EventSource, error) {
+	es := New(url, opts...)
+	return es, es.Connect(ctx)
+}
+
+// New returns an EventSource which does not connect until Connect is called.
+func New(url string, opts ...EventSourceOption) *EventSource {
 	es := &EventSource{
 		d:           nil,
+		id:          newUUID(),
 		url:         url,
 		out:         make(chan *MessageEvent),
 		readyState:  make(chan Status, 128),
+		errs:        make(chan error, 16),
 		closedMutex: new(sync.RWMutex),
+		closing:     make(chan struct{}),
+		closingOnce: new(sync.Once),
+		done:        make(chan struct{}),
+		subs:        make(map[*subscription]struct{}),
+		subsMutex:   new(sync.RWMutex),
+		listeners:   make(map[ListenerID]func()),
+		stateMutex:  new(sync.RWMutex),
+		pauseMutex:  new(sync.Mutex),
+		createdAt:   time.Now(),
+		client:      http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(es)
+	}
+	es.applyDialTimeout()
+	es.retry.Store(int64(es.clampRetry(defaultRetry * time.Millisecond)))
+	return es
+}
+
+// Connect connects the event source returned by New. Once connected, the event
+// source is closed when ctx is done, with the error of ctx as ReadyState error.
+// Like NewEventSource, Connect returns once the first connection attempt is done,
+// and the event source is closed if it failed.
+// Connect can only be called once, and not after Close.
+func (es *EventSource) Connect(ctx context.Context) error {
+	if !es.started.CompareAndSwap(false, true) {
+		return ErrAlreadyStarted
+	}
+	es.closedMutex.Lock()
+	if es.closed {
+		es.closedMutex.Unlock()
+		return ErrClosed
+	}
+	// Canceled by Close, so that no connection attempt outlives the event source
+	es.ctx, es.cancel = context.WithCancel(ctx)
+	es.closedMutex.Unlock()
+	if err := es.loadLastEventID(); err != nil {
+		es.Close(err)
+		return err
+	}
+	es.startIDSaver()
+	if err := es.startHealthServer(); err != nil {
+		es.Close(err)
+		return err
 	}
-	return es, es.connect()
+	go es.closeOnDone(ctx)
+	if err := es.connect(); err != nil {
+		return err
+	}
+	es.startLease()
+	return nil
 }
 
 // connect does a connection attempt, if the operation fails, attempt reconnecting
 // according to the spec.
 func (es *EventSource) connect() (err error) {
 	err = es.connectOnce()
+	// Every failover URL is tried once, see failover.go
+	for i := 0; err != nil && i < len(es.failoverURLs) && es.failOver(err); i++ {
+		es.notifyError(err)
+		err = es.connectOnce()
+	}
 	if err != nil {
 		es.Close(err)
 	}
@@ -55,10 +254,59 @@ func (es *EventSource) connect() (err error) {
 
 // reconnect to the stream several until the operation succeeds or the conditions
 // to retry no longer hold true.
-func (es *EventSource) reconnect() (err error) {
-	for es.mustReconnect(err) {
-		time.Sleep(time.Duration(es.d.Retry()) * time.Millisecond)
+// cause is the error which ended the previous connection.
+func (es *EventSource) reconnect(cause error) (err error) {
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		delay, ok := es.reconnectDelay(attempt)
+		if !ok {
+			err = &SSEError{Code: ErrCodeRetryExhausted, Message: "giving up reconnecting", Cause: err}
+			break
+		}
+		if after := retryAfter(err); after > delay {
+			delay = after
+		}
+		if es.retryBudget > 0 && time.Since(start)+delay >= es.retryBudget {
+			// The attempt would start past the deadline
+			if err == nil {
+				err = cause
+			}
+			err = &SSEError{Code: ErrCodeRetryExhausted, Message: "giving up reconnecting", Cause: err}
+			break
+		}
+		if !es.sleep(delay) || !es.waitResume() || !es.waitOnline() {
+			return nil
+		}
+		es.refreshServiceURL(err) // See discovery.go
+		es.stateMutex.Lock()
+		es.lastReconnect = time.Now()
+		es.stateMutex.Unlock()
+		if err != nil {
+			cause = err
+		}
+		es.sendMetaReconnecting(attempt)
+		es.reconnectInfo = &ReconnectInfo{Attempt: attempt, Cause: cause, Delay: delay}
+		if es.retryBudget > 0 {
+			es.connectDeadline = start.Add(es.retryBudget)
+		}
+		// A successful attempt hands over to a new consume goroutine
 		err = es.connectOnce()
+		if err == nil {
+			break
+		}
+		es.notifyError(err)
+		es.sendMetaError(err)
+		if !es.failOver(err) && !es.mustReconnect(err) {
+			break
+		}
+		if (es.maxAttempts > 0 && attempt+1 >= es.maxAttempts) || (es.retryBudget > 0 && time.Since(start) >= es.retryBudget) {
+			err = &SSEError{Code: ErrCodeRetryExhausted, Message: "giving up reconnecting", Cause: err}
+			break
+		}
+		es.logf("eventsource: connection to %s failed: %v", es.url, err)
+		if !es.waitCircuit() { // See circuit.go
+			return nil
+		}
 	}
 	if err != nil {
 		es.Close(err)
@@ -66,57 +314,329 @@ func (es *EventSource) reconnect() (err error) {
 	return
 }
 
+// reconnectDelay returns the time to wait before the given reconnection attempt,
+// starting at 0, and false if the event source must give up reconnecting.
+// Unless a reconnection strategy or backoff is configured, the retry time of the
+// decoder is used, clamped by WithMinRetry and WithMaxRetry.
+func (es *EventSource) reconnectDelay(attempt int) (time.Duration, bool) {
+	strategy := es.strategy
+	if strategy == nil && es.backoffBase > 0 {
+		strategy = ExponentialBackoff(es.backoffBase, es.backoffMax)
+	}
+	if strategy == nil {
+		return es.clampRetry(time.Duration(es.d.Retry()) * time.Millisecond), true
+	}
+	var serverRetry time.Duration
+	if retry, ok := es.d.serverRetry(); ok {
+		serverRetry = es.clampRetry(retry)
+	}
+	return strategy.NextDelay(attempt, serverRetry)
+}
+
 // Attempts to connect and updates internal status depending on the outcome.
 func (es *EventSource) connectOnce() (err error) {
-	es.readyState <- Status{Connecting, nil}
-	es.resp, err = es.doHTTPConnect()
+	es.setReadyState(Status{Connecting, nil})
+	atomic.AddUint64(&es.connectAttempts, 1)
+	var resp *http.Response
+	if es.wsActive {
+		resp, err = es.doWebSocketConnect()
+	} else {
+		resp, err = es.doHTTPConnect()
+		if err != nil && es.mustFallBack(err) {
+			es.logf("eventsource: falling back to WebSocket %s: %v", es.wsURL, err)
+			resp, err = es.doWebSocketConnect()
+		}
+	}
+	// Cleared before handing over to the consume goroutine, which may reconnect
+	es.reconnectInfo = nil
+	es.connectDeadline = time.Time{}
+	es.recordConnectAttempt(err) // See stats.go
+	// Guarded by closedMutex, so that Close either closes the connection or
+	// prevents it from being used
+	es.closedMutex.Lock()
+	if es.closed {
+		es.closedMutex.Unlock()
+		if err == nil {
+			resp.Body.Close()
+		}
+		return ErrClosed
+	}
+	es.resp = resp
+	es.closedMutex.Unlock()
 	if err != nil {
 		return
 	}
-	es.readyState <- Status{Open, nil}
-	es.d = NewDecoder(es.resp.Body)
-	go es.consume()
+	// Pause closes the connection once established, see pause.go
+	if es.isPaused() {
+		resp.Body.Close()
+	}
+	es.setReadyState(Status{Open, nil})
+	var body io.Reader = es.resp.Body
+	if es.watchdog > 0 {
+		body = newWatchdogReader(es.resp.Body, es.watchdog)
+	}
+	es.d = NewDecoder(&countingReader{r: body, n: &es.bytesReceived, last: &es.lastContact})
+	go es.consume(es.Stats().ConnectCount > 1)
 	return
 }
 
+// prepareRequest applies the headers of the options to req and authenticates it.
+// For the requests connecting to the stream, connect also runs the reconnect
+// handler and the request rewriter.
+func (es *EventSource) prepareRequest(req *http.Request, connect bool) error {
+	for _, decorate := range es.decorators {
+		decorate(req)
+	}
+	if connect {
+		if es.onReconnect != nil && es.reconnectInfo != nil {
+			es.onReconnect(req, *es.reconnectInfo)
+		}
+		if es.rewriter != nil {
+			if err := es.rewriter(req); err != nil {
+				return &SSEError{Code: ErrCodeNetwork, Message: "cannot rewrite request", Cause: err}
+			}
+		}
+	}
+	// Signing comes last, once the request is complete
+	if err := es.authorize(req); err != nil {
+		return err
+	}
+	return es.sign(req)
+}
+
 func (es *EventSource) doHTTPConnect() (*http.Response, error) {
 	// Prepare request
-	req, err := http.NewRequest("GET", es.url, nil)
+	method := es.method
+	if method == "" {
+		method = http.MethodGet
+	}
+	var body io.ReadCloser
+	var contentType string
+	if es.bodyFactory != nil {
+		body, contentType = es.bodyFactory()
+	}
+	req, err := http.NewRequestWithContext(es.ctx, method, es.url, body)
 	if err != nil {
-		return nil, err
+		if body != nil {
+			body.Close()
+		}
+		return nil, &SSEError{Code: ErrCodeNetwork, Message: "cannot create request", Cause: err}
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	accept := es.accept
+	if accept == "" {
+		accept = allowedContentType
 	}
-	req.Header.Set("Accept", allowedContentType)
+	req.Header.Set("Accept", accept)
 	req.Header.Set("Cache-Control", "no-store")
-	if es.lastEventID != "" {
+	if es.lastEventID != "" && !es.omitIDHeader {
 		req.Header.Set("Last-Event-ID", es.lastEventID)
 	}
+	if es.lastEventID != "" && es.idQueryParam != "" {
+		query := req.URL.Query()
+		query.Set(es.idQueryParam, es.lastEventID)
+		req.URL.RawQuery = query.Encode()
+	}
+	if err = es.prepareRequest(req, true); err != nil {
+		if body != nil {
+			body.Close()
+		}
+		return nil, err
+	}
 
 	// Check response
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := es.do(req) // See connect_timeout.go
 	if err != nil {
-		return resp, err
+		return resp, &SSEError{Code: ErrCodeNetwork, Message: "cannot connect", Cause: err}
 	}
-	if resp.Header.Get("Content-Type") != allowedContentType {
-		return resp, ErrContentType
+	es.trackRedirects(resp) // See redirect.go
+	if es.validator != nil {
+		if err := es.validator(resp); err != nil {
+			resp.Body.Close()
+			return resp, err
+		}
+	}
+	// 204 No Content is handled when deciding whether to reconnect
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		resp.Body.Close()
+		return resp, &SSEError{
+			Code:       ErrCodeHTTPStatus,
+			StatusCode: resp.StatusCode,
+			Cause:      es.newHTTPStatusError(resp, es.url), // See status_policy.go
+		}
+	}
+	if !es.acceptsContentType(resp.Header.Get("Content-Type")) {
+		resp.Body.Close()
+		return resp, &SSEError{Code: ErrCodeContentType, StatusCode: resp.StatusCode, Cause: ErrContentType}
 	}
 	return resp, nil
 }
 
+// closeOnDone closes the event source when its context is done.
+func (es *EventSource) closeOnDone(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		es.Close(ctx.Err())
+	case <-es.closing:
+	}
+}
+
+// acceptsContentType tells whether the stream may have the given content type.
+// Unless set with WithContentTypes, only allowedContentType without parameters is accepted.
+func (es *EventSource) acceptsContentType(contentType string) bool {
+	if len(es.contentTypes) == 0 {
+		return contentType == allowedContentType
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range es.contentTypes {
+		if strings.EqualFold(mediaType, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
 // Method consume() must be called once connect() succeeds.
 // It parses the input reader and assigns the event output channel accordingly.
-func (es *EventSource) consume() {
+// On reconnection, the events missed meanwhile are first fetched from the event cache, if any.
+func (es *EventSource) consume(reconnected bool) {
+	// Id sent on reconnection, see WithResumeHandler
+	var resumedFrom string
+	if reconnected && es.onResume != nil {
+		resumedFrom = es.LastEventID()
+	}
+	if reconnected && es.cacheURL != "" && !es.replayCache() {
+		return
+	}
+	es.sendMeta(MetaEventOpen, "") // See meta_events.go
 	for {
 		ev, err := es.d.Decode()
+		es.updateRetry() // See retry.go
 		if err != nil {
+			es.stateMutex.Lock()
+			es.disconnectCount++
+			es.stateMutex.Unlock()
+			if es.isPaused() && !es.isClosed() {
+				es.pause() // See pause.go
+				return
+			}
+			if err == io.EOF && es.resp != nil && es.resp.StatusCode == http.StatusNoContent {
+				err = ErrNoContent
+			}
+			if err == io.EOF {
+				es.notifyError(ErrStreamClosed)
+				es.sendMetaError(ErrStreamClosed)
+			} else {
+				es.notifyError(err)
+				es.sendMetaError(err)
+			}
 			if es.mustReconnect(err) {
-				es.reconnect()
+				es.reconnect(err)
 			} else {
 				es.Close(err)
 			}
 			return
 		}
-		es.lastEventID = ev.LastEventID
-		es.out <- ev
+		if es.isAdvisory(ev) {
+			es.advisedReconnect(ev) // See advisory.go
+			return
+		}
+		if resumedFrom != "" {
+			es.onResume(resumedFrom, ev.LastEventID)
+			resumedFrom = ""
+		}
+		if !es.dispatch(ev) {
+			return
+		}
+	}
+}
+
+// dispatch delivers ev to the subscriptions, or the events channel if there is none.
+// It returns false if the event source is closed.
+func (es *EventSource) dispatch(ev *MessageEvent) bool {
+	es.stateMutex.Lock()
+	changed := es.lastEventID != ev.LastEventID
+	es.lastEventID = ev.LastEventID
+	es.eventsReceived++
+	es.stateMutex.Unlock()
+	if changed {
+		es.saveLastEventID(ev.LastEventID)
+	}
+	if es.publish(ev) {
+		// Subscribers take over MessageEvents, see Subscribe
+		return !es.isClosed()
+	}
+	return es.send(ev)
+}
+
+// send blocks until ev is received or the event source is closed.
+// It returns false if the event source is closed.
+func (es *EventSource) send(ev *MessageEvent) bool {
+	es.closedMutex.RLock()
+	defer es.closedMutex.RUnlock()
+	if es.closed {
+		return false
+	}
+	if es.overflow != OverflowBlock {
+		es.sendOrDrop(ev) // See overflow.go
+		return true
+	}
+	select {
+	case es.out <- ev:
+		return true
+	case <-es.closing:
+		es.stateMutex.Lock()
+		es.undelivered = append(es.undelivered, ev)
+		es.stateMutex.Unlock()
+		return false
+	}
+}
+
+// notifyError sends err on the errors channel, unless it is full or the
+// event source is closed.
+func (es *EventSource) notifyError(err error) {
+	es.closedMutex.RLock()
+	if es.closed {
+		es.closedMutex.RUnlock()
+		return
+	}
+	select {
+	case es.errs <- err:
+	default:
+	}
+	es.closedMutex.RUnlock()
+	// Called without lock, so that the handler may close the event source
+	if es.onError != nil {
+		es.onError(err)
+	}
+}
+
+func (es *EventSource) logf(format string, v ...interface{}) {
+	if es.logger != nil {
+		es.logger.Printf("%s connection_id=%s", fmt.Sprintf(format, v...), es.id)
+	}
+}
+
+func (es *EventSource) isClosed() bool {
+	es.closedMutex.RLock()
+	defer es.closedMutex.RUnlock()
+	return es.closed
+}
+
+// sleep waits for d, and returns false if the event source is closed meanwhile.
+func (es *EventSource) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return !es.isClosed()
+	case <-es.closing:
+		return false
 	}
 }
 
@@ -128,10 +648,14 @@ func (es *EventSource) mustReconnect(err error) bool {
 	if es.closed {
 		return false
 	}
-	switch err {
-	case ErrContentType:
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Reconnect
+	}
+	if hasErrorCode(err, ErrCodeContentType) || hasErrorCode(err, ErrCodeHTTPStatus) {
 		return false
-	case io.ErrUnexpectedEOF:
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
 		return true
 	}
 	if es.resp != nil && es.resp.StatusCode == http.StatusNoContent {
@@ -140,37 +664,181 @@ func (es *EventSource) mustReconnect(err error) bool {
 	return true
 }
 
+// ID returns the unique identifier of the event source, generated on creation.
+// It is included as connection_id in the messages sent to the logger.
+func (es *EventSource) ID() string {
+	return es.id
+}
+
+// Logger returns the logger set with WithLogger, nil unless WithLogger is used.
+func (es *EventSource) Logger() *log.Logger {
+	return es.logger
+}
+
+// SessionID returns the session id sent with every request, see WithSessionID.
+// It is empty unless WithSessionID is used.
+func (es *EventSource) SessionID() string {
+	return es.sessionID
+}
+
+// Fingerprint returns the value of the header set with WithFingerprint.
+// It is empty unless WithFingerprint is used.
+func (es *EventSource) Fingerprint() string {
+	return es.fingerprint
+}
+
+// LastEventID returns the id of the last event received, sent in the Last-Event-ID
+// header on reconnection.
+func (es *EventSource) LastEventID() string {
+	es.stateMutex.RLock()
+	defer es.stateMutex.RUnlock()
+	return es.lastEventID
+}
+
+// SetLastEventID sets the Last-Event-ID header of the first connection, e.g. to
+// resume from a persisted position, on an event source returned by New.
+// It fails with ErrAlreadyStarted once Connect is called. See WithLastEventID.
+func (es *EventSource) SetLastEventID(id string) error {
+	if es.started.Load() {
+		return ErrAlreadyStarted
+	}
+	es.stateMutex.Lock()
+	es.lastEventID = id
+	es.stateMutex.Unlock()
+	return nil
+}
+
 // URL returns the event source URL.
+// It may change on reconnection for event sources created by NewEventSourceFromService.
 func (es *EventSource) URL() string {
+	es.stateMutex.RLock()
+	defer es.stateMutex.RUnlock()
 	return es.url
 }
 
 // MessageEvents returns a channel of received events.
+// Events are not filtered by name: every event is received, whatever its name.
+// See SubscribeAll for several consumers to receive every event.
 func (es *EventSource) MessageEvents() <-chan *MessageEvent {
 	return es.out
 }
 
+// Errors returns a channel of the errors met while the event source is running.
+// Among others, ErrStreamClosed is received every time the server closes the stream
+// cleanly, and the error of every failed reconnection attempt is received.
+// Errors are dropped if the channel is full, hence consuming it is optional.
+// See WithErrorHandler to handle all of them.
+// The channel is closed once the event source is closed.
+func (es *EventSource) Errors() <-chan error {
+	return es.errs
+}
+
+// State returns the current ready state of the event source.
+func (es *EventSource) State() ReadyState {
+	return ReadyState(es.state.Load())
+}
+
 // ReadyState exposes a channel with updates on the ready state
 // of the event source.
-// It must be consumed together with MessageEvents.
+// Updates are dropped if the channel is full, hence consuming it is optional.
+// See WithStateChangeHandler to be notified of every change with a callback.
 func (es *EventSource) ReadyState() <-chan Status {
 	return es.readyState
 }
 
 // Close the event source. Once closed, the event source cannot be re-used again.
+// Pending connection attempts are canceled, and Close interrupts the delay before
+// reconnecting: no connection is established once Close is called.
 func (es *EventSource) Close(err error) {
+	// Unblock any pending send before closing the channels
+	es.closingOnce.Do(func() { close(es.closing) })
 	es.closedMutex.Lock()
 	defer es.closedMutex.Unlock()
 	if es.closed {
 		return
 	}
-	es.readyState <- Status{Closing, err}
+	es.setReadyState(Status{Closing, err})
 	es.closed = true
+	if es.cancel != nil {
+		es.cancel()
+	}
 
 	if es.resp != nil {
 		es.resp.Body.Close()
 	}
 
+	es.closeSubscriptions()
 	close(es.out)
-	es.readyState <- Status{Closed, err}
+	close(es.errs)
+	es.stopHealthServer()
+	es.err = err
+	es.setReadyState(Status{Closed, err})
+	close(es.done)
+}
+
+// Done returns a channel closed once the event source is closed, see Err.
+func (es *EventSource) Done() <-chan struct{} {
+	return es.done
+}
+
+// Err returns the reason why the event source was closed, which is also the
+// error of the Closed ready state: e.g. the error of the context given to
+// NewEventSourceWithContext, the last connection error or nil when closed by
+// Close(nil). It returns nil until Done is closed.
+func (es *EventSource) Err() error {
+	es.closedMutex.RLock()
+	defer es.closedMutex.RUnlock()
+	return es.err
+}
+
+// Drain closes the event source and returns the events received but not yet
+// delivered on the MessageEvents channel, so that they can be processed before
+// tearing down. No event is received once Drain is called.
+// Drain blocks until the event source is closed. It returns an empty slice if
+// the event source is already closed.
+func (es *EventSource) Drain() []*MessageEvent {
+	if es.isClosed() {
+		return []*MessageEvent{}
+	}
+	es.Close(nil)
+	events := []*MessageEvent{}
+	// Buffered events, see WithEventBufferSize
+	for ev := range es.out {
+		events = append(events, ev)
+	}
+	es.stateMutex.Lock()
+	defer es.stateMutex.Unlock()
+	events = append(events, es.undelivered...)
+	es.undelivered = nil
+	return events
+}
+
+// setReadyState updates the current state and notifies the change.
+func (es *EventSource) setReadyState(status Status) {
+	previous := ReadyState(es.state.Swap(uint32(status.ReadyState)))
+	// Never block, as for errors: the channel may not be consumed
+	select {
+	case es.readyState <- status:
+	default:
+	}
+	if es.onStateChange != nil && previous != status.ReadyState {
+		es.onStateChange(previous, status.ReadyState)
+	}
+}
+
+// countingReader counts the bytes read from r, and records the time of the
+// last read returning data in last, in nanoseconds since the Unix epoch.
+type countingReader struct {
+	r    io.Reader
+	n    *uint64
+	last *atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if n > 0 {
+		atomic.AddUint64(c.n, uint64(n))
+		c.last.Store(time.Now().UnixNano())
+	}
+	return n, err
 }
@@ -0,0 +1,336 @@
+package sse
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// EventSourceOption configures an EventSource.
+type EventSourceOption func(*EventSource)
+
+// WithLogger sets the logger used to report connection failures.
+// Nothing is logged by default.
+func WithLogger(logger *log.Logger) EventSourceOption {
+	return func(es *EventSource) {
+		es.logger = logger
+	}
+}
+
+// WithErrorHandler calls fn with every error sent on the Errors channel, even
+// when the channel is full. fn is called synchronously by the event source: it
+// may close the event source but must not block.
+func WithErrorHandler(fn func(err error)) EventSourceOption {
+	return func(es *EventSource) {
+		es.onError = fn
+	}
+}
+
+// WithStateChangeHandler calls fn every time the ready state of the event source
+// changes, e.g. from Open to Connecting when the connection is lost.
+// fn is called synchronously by the event source: it must neither block nor close
+// the event source.
+func WithStateChangeHandler(fn func(from, to ReadyState)) EventSourceOption {
+	return func(es *EventSource) {
+		es.onStateChange = fn
+	}
+}
+
+// WithHTTPClient sets the client used to connect to the stream.
+// By default, http.DefaultClient is used.
+func WithHTTPClient(client *http.Client) EventSourceOption {
+	return func(es *EventSource) {
+		es.client = client
+	}
+}
+
+// WithTransport sets the transport used to connect to the stream, e.g. to
+// configure TLS or proxies. The other settings of the client set with
+// WithHTTPClient, if given before, are kept.
+func WithTransport(rt http.RoundTripper) EventSourceOption {
+	return func(es *EventSource) {
+		client := http.Client{}
+		if es.client != nil {
+			client = *es.client
+		}
+		client.Transport = rt
+		es.client = &client
+	}
+}
+
+// WithLastEventID sets the Last-Event-ID header of the first connection, e.g. to
+// resume from a persisted position.
+func WithLastEventID(id string) EventSourceOption {
+	return func(es *EventSource) {
+		es.lastEventID = id
+	}
+}
+
+// WithLastEventIDQuery also sends the last event id in the name query parameter
+// on reconnection, for gateways stripping the Last-Event-ID header.
+// See WithoutLastEventIDHeader to only send the query parameter.
+func WithLastEventIDQuery(name string) EventSourceOption {
+	return func(es *EventSource) {
+		es.idQueryParam = name
+	}
+}
+
+// WithoutLastEventIDHeader does not send the Last-Event-ID header on reconnection.
+// It is meant to be used with WithLastEventIDQuery.
+func WithoutLastEventIDHeader() EventSourceOption {
+	return func(es *EventSource) {
+		es.omitIDHeader = true
+	}
+}
+
+// WithAccept sets the Accept header of the requests, text/event-stream by default.
+func WithAccept(accept string) EventSourceOption {
+	return func(es *EventSource) {
+		es.accept = accept
+	}
+}
+
+// WithContentTypes sets the media types allowed for the stream, e.g. vendor
+// types. Their parameters, such as charset, are ignored.
+// By default, only text/event-stream without parameters is allowed; other
+// content types fail with ErrContentType.
+func WithContentTypes(mediaTypes ...string) EventSourceOption {
+	return func(es *EventSource) {
+		es.contentTypes = mediaTypes
+	}
+}
+
+// WithResponseValidator calls fn with every response to a connection attempt,
+// before the status code and content type are checked and the stream is read.
+// If fn returns an error, the attempt fails with it: the event source reconnects,
+// unless the error is an *SSEError with the ErrCodeHTTPStatus or ErrCodeContentType
+// code, in which case it is closed.
+func WithResponseValidator(fn func(*http.Response) error) EventSourceOption {
+	return func(es *EventSource) {
+		es.validator = fn
+	}
+}
+
+// ReconnectInfo describes a reconnection attempt, see WithReconnectHandler.
+type ReconnectInfo struct {
+	// Attempt number, starting at 0 after every disconnection.
+	Attempt int
+	// Cause is the error which ended the connection on the first attempt, and
+	// the error of the previous attempt afterwards.
+	Cause error
+	// Delay waited before the attempt.
+	Delay time.Duration
+}
+
+// WithReconnectHandler calls fn with the request of every reconnection attempt,
+// before it is sent, so that it can be observed or modified, e.g. with a fresh
+// token. WebSocket reconnections, see WithWebSocketFallback, are not included.
+// fn is called by the reconnection loop: it must not block indefinitely.
+func WithReconnectHandler(fn func(req *http.Request, info ReconnectInfo)) EventSourceOption {
+	return func(es *EventSource) {
+		es.onReconnect = fn
+	}
+}
+
+// WithRequestRewriter calls fn with the request of every connection attempt,
+// including the first one, before it is sent, e.g. to replace its URL with a
+// freshly pre-signed one. The Last-Event-ID query parameter, see
+// WithLastEventIDQuery, is already set and must be preserved.
+// If fn fails, the attempt fails with an *SSEError of code ErrCodeNetwork
+// wrapping the error, and the event source reconnects.
+func WithRequestRewriter(fn func(req *http.Request) error) EventSourceOption {
+	return func(es *EventSource) {
+		es.rewriter = fn
+	}
+}
+
+// WithResumeHandler calls fn on reconnection with the last event id sent to the
+// server and the id of the first event received, empty if it has none, so that
+// gaps can be detected, e.g. when the server no longer has the events missed
+// meanwhile, and the application state fully resynchronized.
+// fn is not called when no event was received before the reconnection. It is
+// called by the goroutine delivering the events, before the first event.
+func WithResumeHandler(fn func(lastEventID, firstEventID string)) EventSourceOption {
+	return func(es *EventSource) {
+		es.onResume = fn
+	}
+}
+
+// WithHeader sets the name header to value on every request, including reconnections.
+func WithHeader(name, value string) EventSourceOption {
+	return func(es *EventSource) {
+		es.decorators = append(es.decorators, func(req *http.Request) {
+			req.Header.Set(name, value)
+		})
+	}
+}
+
+// WithEventBufferSize buffers up to size events on the MessageEvents channel,
+// so that the event source keeps reading the stream while the consumer is busy.
+// The channel is unbuffered by default.
+func WithEventBufferSize(size int) EventSourceOption {
+	return func(es *EventSource) {
+		es.out = make(chan *MessageEvent, size)
+	}
+}
+
+// WithDialTimeout limits the time spent establishing the TCP connection to the server.
+// It only applies to clients using an *http.Transport.
+func WithDialTimeout(d time.Duration) EventSourceOption {
+	return func(es *EventSource) {
+		es.dialTimeout = d
+	}
+}
+
+// WithExponentialBackoff waits base before the first reconnection attempt, then
+// doubles the delay on every failed attempt up to max. The retry time sent by the
+// server, if any, is the minimum delay, even when greater than max.
+// See WithBackoff for other strategies.
+func WithExponentialBackoff(base, max time.Duration) EventSourceOption {
+	return func(es *EventSource) {
+		es.backoffBase = base
+		es.backoffMax = max
+	}
+}
+
+// applyDialTimeout replaces the client by a copy whose transport enforces the dial timeout.
+func (es *EventSource) applyDialTimeout() {
+	if es.dialTimeout <= 0 {
+		return
+	}
+	transport := es.client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	t, ok := transport.(*http.Transport)
+	if !ok {
+		es.logf("eventsource: dial timeout ignored, the client transport is not an *http.Transport")
+		return
+	}
+	t = t.Clone()
+	t.DialContext = (&net.Dialer{Timeout: es.dialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	client := *es.client
+	client.Transport = t
+	es.client = &client
+}
+
+// WithMaxReconnectAttempts closes the event source after n consecutive failed
+// reconnection attempts, with an *SSEError of code ErrCodeRetryExhausted wrapping
+// the error of the last attempt. By default, the event source reconnects forever.
+func WithMaxReconnectAttempts(n int) EventSourceOption {
+	return func(es *EventSource) {
+		es.maxAttempts = n
+	}
+}
+
+// WithReconnectBudget closes the event source once reconnection attempts failed
+// for d since the connection was lost, with an *SSEError of code
+// ErrCodeRetryExhausted wrapping the error of the last attempt.
+// Attempts are aborted once the budget is spent, like with WithConnectTimeout,
+// and the event source gives up without waiting when the next attempt would
+// start too late. See WithConnectTimeout to also bound every attempt.
+func WithReconnectBudget(d time.Duration) EventSourceOption {
+	return func(es *EventSource) {
+		es.retryBudget = d
+	}
+}
+
+// WithMethod sets the HTTP method of the requests connecting to the stream, for
+// servers expecting e.g. a POST to initiate the subscription. The default is GET.
+func WithMethod(method string) EventSourceOption {
+	return func(es *EventSource) {
+		es.method = method
+	}
+}
+
+// WithRequestBodyFactory sets the body of the requests connecting to the stream.
+// fn is called before every connection attempt, as a body cannot be sent twice,
+// and returns the body together with its content type.
+// It is meant to be used with WithMethod.
+func WithRequestBodyFactory(fn func() (body io.ReadCloser, contentType string)) EventSourceOption {
+	return func(es *EventSource) {
+		es.bodyFactory = fn
+	}
+}
+
+// WithRequestBody sends body with the given content type in every request
+// connecting to the stream, e.g. a JSON payload for streaming APIs.
+// The method is POST unless set with WithMethod.
+func WithRequestBody(body []byte, contentType string) EventSourceOption {
+	return func(es *EventSource) {
+		if es.method == "" {
+			es.method = http.MethodPost
+		}
+		es.bodyFactory = func() (io.ReadCloser, string) {
+			return io.NopCloser(bytes.NewReader(body)), contentType
+		}
+	}
+}
+
+// DefaultSessionHeader is the request header carrying the session id, see WithSessionID.
+const DefaultSessionHeader = "X-Session-ID"
+
+// WithSessionID sends id in a header of every request, including reconnections,
+// e.g. for sticky load-balancer routing. If id is empty, a random UUID is generated
+// once for the whole lifetime of the event source.
+// The header is DefaultSessionHeader unless set with WithSessionHeader.
+func WithSessionID(id string) EventSourceOption {
+	return func(es *EventSource) {
+		es.sessionID = withStickyHeader(es, id, func() string {
+			if es.sessionHeader == "" {
+				return DefaultSessionHeader
+			}
+			return es.sessionHeader
+		})
+	}
+}
+
+// WithSessionHeader sets the header carrying the session id set with WithSessionID.
+func WithSessionHeader(name string) EventSourceOption {
+	return func(es *EventSource) {
+		es.sessionHeader = name
+	}
+}
+
+// WithFingerprint sends value in the headerName header of every request,
+// including reconnections, so that load balancers can route all the requests
+// of the event source to the same backend. If value is empty, a random UUID
+// is generated once for the whole lifetime of the event source.
+func WithFingerprint(headerName, value string) EventSourceOption {
+	return func(es *EventSource) {
+		es.fingerprint = withStickyHeader(es, value, func() string { return headerName })
+	}
+}
+
+// withStickyHeader sends value, or a random UUID if it is empty, in the header
+// returned by header on every request, and returns the value sent.
+func withStickyHeader(es *EventSource, value string, header func() string) string {
+	if value == "" {
+		value = newUUID()
+	}
+	es.decorators = append(es.decorators, func(req *http.Request) {
+		req.Header.Set(header(), value)
+	})
+	return value
+}
+
+// WithAuthHeaders calls fn before every request, including reconnections, and
+// sets the headers it returns on the request, replacing any previous values.
+// Any header can be provided this way, e.g. refreshed tokens, tenant ids or
+// tracing headers; WithHeader is enough for static values.
+// fn is called by the reconnection loop: it must not block indefinitely.
+func WithAuthHeaders(fn func() http.Header) EventSourceOption {
+	return func(es *EventSource) {
+		es.decorators = append(es.decorators, func(req *http.Request) {
+			for name, values := range fn() {
+				req.Header.Del(name)
+				for _, value := range values {
+					req.Header.Add(name, value)
+				}
+			}
+		})
+	}
+}
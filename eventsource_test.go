@@ -1,6 +1,19 @@
 package sse
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -60,11 +73,34 @@ func TestEventSourceWithInvalidContentType(t *testing.T) {
 		handler.ContentType = contentTypeTextPlain
 		es, err := NewEventSource(handler.URL)
 
-		assert.Equal(t, ErrContentType, err)
+		assert.True(t, errors.Is(err, ErrContentType))
+		assert.True(t, hasErrorCode(err, ErrCodeContentType))
 		assertStates(t, []ReadyState{Connecting, Closing, Closed}, es.ReadyState())
 	})
 }
 
+func TestEventSourceStopsOnNoContent(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, Status{Connecting, nil}, <-es.ReadyState())
+	assert.Equal(t, Status{Open, nil}, <-es.ReadyState())
+	assert.Equal(t, Status{Closing, ErrNoContent}, <-es.ReadyState())
+	assert.Equal(t, Status{Closed, ErrNoContent}, <-es.ReadyState())
+	assert.Equal(t, ErrNoContent, <-es.Errors())
+	assert.Equal(t, ErrNoContent, es.Err())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
 func TestEventSourceConnectWriteAndReceiveShortEvent(t *testing.T) {
 	runTest(t, func(handler *testutils.TestServerHandler) {
 		es, err := NewEventSource(handler.URL)
@@ -198,13 +234,895 @@ func TestEventSourceLastEventIDHeaderOnReconnecting(t *testing.T) {
 		go handler.SendAndCloseWithID(newMessageEventString("first", "", 128), "first")
 		_, ok := <-es.MessageEvents()
 		assert.True(t, ok)
-		assert.Equal(t, "first", es.lastEventID)
+		assert.Equal(t, "first", es.LastEventID())
 
 		go handler.SendWithID(newMessageEventString("second", "", 128), "second")
 		_, ok = <-es.MessageEvents()
 		assert.True(t, ok)
-		assert.Equal(t, "second", es.lastEventID)
+		assert.Equal(t, "second", es.LastEventID())
+	})
+}
+
+func TestEventSourceMessageEventsReceivesNamedAndUnnamedEvents(t *testing.T) {
+	runTest(t, func(handler *testutils.TestServerHandler) {
+		es, err := NewEventSource(handler.URL)
+		assert.Nil(t, err)
+
+		go handler.Send("data: unnamed\n\nevent: update\ndata: named\n\nevent: alert\ndata: other\n\n")
+
+		var names []string
+		for i := 0; i < 3; i++ {
+			ev, ok := <-es.MessageEvents()
+			assert.True(t, ok)
+			names = append(names, ev.Name)
+		}
+		assert.Equal(t, []string{"", "update", "alert"}, names)
+	})
+}
+
+func TestEventSourceErrStreamClosed(t *testing.T) {
+	runTest(t, func(handler *testutils.TestServerHandler) {
+		es, err := NewEventSource(handler.URL)
+		assert.Nil(t, err)
+
+		go handler.SendAndClose(retryEventToString(1) + newMessageEventString("", "", 32))
+		<-es.MessageEvents()
+
+		err = <-es.Errors()
+		assert.True(t, errors.Is(err, ErrStreamClosed))
+		assert.False(t, errors.Is(err, ErrContentType))
+
+		// No more requests are accepted, the server answers 204 and the
+		// event source is closed
+		_, ok := <-es.MessageEvents()
+		assert.False(t, ok)
+		for range es.Errors() {
+		}
+	})
+}
+
+func TestEventSourceIDIsUnique(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	first, _ := NewEventSource(server.URL)
+	second, _ := NewEventSource(server.URL)
+
+	assert.Regexp(t, "^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$", first.ID())
+	assert.NotEqual(t, first.ID(), second.ID())
+}
+
+func TestEventSourceLogsConnectionID(t *testing.T) {
+	out := new(syncBuffer)
+	es := &EventSource{id: newUUID(), logger: log.New(out, "", 0)}
+	es.logf("eventsource: %s", "message")
+	assert.Equal(t, "eventsource: message connection_id="+es.ID()+"\n", out.String())
+}
+
+func TestEventSourceStateIsSafeForConcurrentUse(t *testing.T) {
+	// The stream ends right away, so the event source keeps reconnecting
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("retry: 1\n\n"))
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+
+	var wg sync.WaitGroup
+	deadline := time.Now().Add(100 * time.Millisecond)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for time.Now().Before(deadline) {
+				assert.LessOrEqual(t, uint16(es.State()), uint16(Closed))
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Greater(t, atomic.LoadUint64(&es.connectAttempts), uint64(1))
+	es.Close(nil)
+	assert.Equal(t, Closed, es.State())
+}
+
+func TestEventSourceWithPostMethod(t *testing.T) {
+	type request struct {
+		body        string
+		contentType string
+		lastEventID string
+	}
+	requests := make(chan request, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		body, _ := io.ReadAll(req.Body)
+		requests <- request{string(body), req.Header.Get("Content-Type"), req.Header.Get("Last-Event-ID")}
+		rw.Header().Set("Content-Type", allowedContentType)
+		if req.Header.Get("Last-Event-ID") == "" {
+			// Force a reconnection
+			rw.Write([]byte("retry: 1\nid: 1\ndata: first\n\n"))
+			return
+		}
+		rw.Write([]byte("data: second\n\n"))
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL,
+		WithMethod(http.MethodPost),
+		WithRequestBodyFactory(func() (io.ReadCloser, string) {
+			return io.NopCloser(strings.NewReader(`{"topic":"quotes"}`)), "application/json"
+		}),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+
+	assert.Equal(t, "first", (<-es.MessageEvents()).Data)
+	assert.Equal(t, "second", (<-es.MessageEvents()).Data)
+	assert.Equal(t, request{`{"topic":"quotes"}`, "application/json", ""}, <-requests)
+	assert.Equal(t, request{`{"topic":"quotes"}`, "application/json", "1"}, <-requests)
+}
+
+func TestEventSourceWithRequestBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		if req.Method != http.MethodPost || string(body) != `{"stream":true}` || req.Header.Get("Content-Type") != "application/json" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	_, err := NewEventSource(server.URL, WithRequestBody([]byte(`{"stream":true}`), "application/json"))
+	assert.NoError(t, err)
+}
+
+func TestEventSourceSessionIDIsKeptOnReconnect(t *testing.T) {
+	server, requests := newReconnectingServer(1)
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL, WithSessionID(""), WithSessionHeader("X-Tenant-Session"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	go discardMessageEvents(es)
+	collectStates(es.ReadyState())
+
+	assert.Regexp(t, "^[0-9a-f-]{36}$", es.SessionID())
+	assert.Equal(t, es.SessionID(), (<-requests).Header.Get("X-Tenant-Session"))
+	assert.Equal(t, es.SessionID(), (<-requests).Header.Get("X-Tenant-Session"))
+
+	other, err := NewEventSource(server.URL, WithSessionID("fixed"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer other.Close(nil)
+	assert.Equal(t, "fixed", other.SessionID())
+}
+
+func TestEventSourceFingerprintIsKeptOnReconnect(t *testing.T) {
+	server, requests := newReconnectingServer(1)
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL, WithFingerprint("X-Client-Fingerprint", ""))
+	if !assert.NoError(t, err) {
+		return
+	}
+	go discardMessageEvents(es)
+	collectStates(es.ReadyState())
+
+	assert.Regexp(t, "^[0-9a-f-]{36}$", es.Fingerprint())
+	assert.Equal(t, es.Fingerprint(), (<-requests).Header.Get("X-Client-Fingerprint"))
+	assert.Equal(t, es.Fingerprint(), (<-requests).Header.Get("X-Client-Fingerprint"))
+}
+
+func TestEventSourceAuthHeadersAreRefreshedOnReconnect(t *testing.T) {
+	server, requests := newReconnectingServer(1)
+	defer server.Close()
+
+	var calls int
+	es, err := NewEventSource(server.URL, WithAuthHeaders(func() http.Header {
+		calls++
+		return http.Header{
+			"X-Api-Key":   {"key"},
+			"X-Signature": {"signature-" + strconv.Itoa(calls)},
+			"Accept":      {"text/event-stream", "application/json"},
+		}
+	}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	go discardMessageEvents(es)
+	collectStates(es.ReadyState())
+
+	for _, signature := range []string{"signature-1", "signature-2"} {
+		header := (<-requests).Header
+		assert.Equal(t, "key", header.Get("X-Api-Key"))
+		assert.Equal(t, signature, header.Get("X-Signature"))
+		assert.Equal(t, []string{"text/event-stream", "application/json"}, header.Values("Accept"))
+	}
+}
+
+func TestEventSourceDrain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("data: first\n\ndata: second\n\n"))
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+	// Wait for the first event to be pending delivery
+	for es.Stats().TotalEventsReceived == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	events := es.Drain()
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "first", events[0].Data)
+	}
+	assert.Equal(t, Closed, es.State())
+	_, ok := <-es.MessageEvents()
+	assert.False(t, ok)
+
+	// Draining a closed event source
+	assert.Equal(t, []*MessageEvent{}, es.Drain())
+}
+
+func TestEventSourceWithHeaderAndEventBuffer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer token" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("data: 1\n\ndata: 2\n\ndata: 3\n\n"))
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL, WithHeader("Authorization", "Bearer token"), WithEventBufferSize(2))
+	if !assert.NoError(t, err) {
+		return
+	}
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+	// Two events are buffered, the third one is pending delivery
+	for es.Stats().TotalEventsReceived < 3 {
+		time.Sleep(time.Millisecond)
+	}
+
+	var data []string
+	for _, ev := range es.Drain() {
+		data = append(data, ev.Data)
+	}
+	assert.Equal(t, []string{"1", "2", "3"}, data)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestEventSourceWithTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	var roundTrips int32
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&roundTrips, 1)
+		return http.DefaultTransport.RoundTrip(req)
 	})
+	client := &http.Client{Timeout: time.Minute}
+	es, err := NewEventSource(server.URL, WithHTTPClient(client), WithTransport(transport))
+	if !assert.NoError(t, err) {
+		return
+	}
+	collectStates(es.ReadyState())
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&roundTrips))
+	assert.Equal(t, time.Minute, es.client.Timeout)
+	assert.Nil(t, client.Transport, "the given client must not be modified")
+}
+
+func TestEventSourceClosedWithContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	es, err := NewEventSourceWithContext(ctx, server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, Status{Connecting, nil}, <-es.ReadyState())
+	assert.Equal(t, Status{Open, nil}, <-es.ReadyState())
+
+	cancel()
+	assert.Equal(t, Status{Closing, context.Canceled}, <-es.ReadyState())
+	assert.Equal(t, Status{Closed, context.Canceled}, <-es.ReadyState())
+	_, ok := <-es.MessageEvents()
+	assert.False(t, ok)
+	<-es.Done()
+	assert.Equal(t, context.Canceled, es.Err())
+}
+
+func TestEventSourceDoneReportsTerminalError(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&requests, 1) > 1 {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("retry: 1\n\n"))
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+	assert.Nil(t, es.Err())
+
+	select {
+	case <-es.Done():
+		assert.True(t, hasErrorCode(es.Err(), ErrCodeHTTPStatus), "unexpected error %v", es.Err())
+	case <-time.After(time.Second):
+		assert.FailNow(t, "event source not closed")
+	}
+}
+
+func TestEventSourceResumesFromLastEventID(t *testing.T) {
+	lastEventIDs := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		lastEventIDs <- req.Header.Get("Last-Event-ID")
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL, WithLastEventID("41"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "41", <-lastEventIDs)
+		assert.Equal(t, "41", es.LastEventID())
+	}
+
+	es = New(server.URL)
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+	assert.NoError(t, es.SetLastEventID("42"))
+	assert.NoError(t, es.Connect(context.Background()))
+	assert.Equal(t, "42", <-lastEventIDs)
+	assert.Equal(t, ErrAlreadyStarted, es.SetLastEventID("43"))
+}
+
+func TestEventSourceWithResumeHandler(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			rw.Write([]byte("retry: 1\nid: 5\ndata: first\n\n"))
+		case 2:
+			// Events 6 to 8 are lost
+			rw.Write([]byte("retry: 1\nid: 9\ndata: second\n\nid: 10\ndata: third\n\n"))
+		default:
+			rw.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	resumes := make(chan [2]string, 2)
+	es, err := NewEventSource(server.URL, WithResumeHandler(func(lastEventID, firstEventID string) {
+		resumes <- [2]string{lastEventID, firstEventID}
+	}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	go discardMessageEvents(es)
+	collectStates(es.ReadyState())
+
+	<-es.Done()
+	assert.Equal(t, [2]string{"5", "9"}, <-resumes)
+	// Not called after the last connection, which received no event
+	assert.Len(t, resumes, 0)
+}
+
+func TestEventSourceLastEventIDQuery(t *testing.T) {
+	type request struct {
+		query, header string
+	}
+	requests := make(chan request, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests <- request{req.URL.RawQuery, req.Header.Get("Last-Event-ID")}
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	NewEventSource(server.URL+"?topic=a", WithLastEventID("1"), WithLastEventIDQuery("lastEventId"))
+	assert.Equal(t, request{"lastEventId=1&topic=a", "1"}, <-requests)
+
+	NewEventSource(server.URL, WithLastEventID("1"), WithLastEventIDQuery("since"), WithoutLastEventIDHeader())
+	assert.Equal(t, request{"since=1", ""}, <-requests)
+
+	NewEventSource(server.URL, WithLastEventIDQuery("since"))
+	assert.Equal(t, request{"", ""}, <-requests)
+}
+
+func TestEventSourceWithContentTypes(t *testing.T) {
+	var contentType atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Accept") != "application/vnd.stream+sse, text/event-stream" {
+			rw.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		rw.Header().Set("Content-Type", contentType.Load().(string))
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	opts := []EventSourceOption{
+		WithAccept("application/vnd.stream+sse, text/event-stream"),
+		WithContentTypes("text/event-stream", "application/vnd.stream+sse"),
+	}
+	for value, allowed := range map[string]bool{
+		"text/event-stream; charset=utf-8": true,
+		"application/vnd.stream+sse":       true,
+		contentTypeTextPlain:               false,
+		"text/event-stream; charset":       false,
+	} {
+		contentType.Store(value)
+		_, err := NewEventSource(server.URL, opts...)
+		if allowed {
+			assert.NoError(t, err, value)
+		} else {
+			assert.True(t, errors.Is(err, ErrContentType), value)
+		}
+	}
+}
+
+type authChallengeError struct {
+	challenge string
+}
+
+func (e *authChallengeError) Error() string {
+	return "authentication required: " + e.challenge
+}
+
+func TestEventSourceWithResponseValidator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("WWW-Authenticate", `Bearer realm="stream"`)
+		rw.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := NewEventSource(server.URL, WithResponseValidator(func(resp *http.Response) error {
+		if resp.StatusCode == http.StatusUnauthorized {
+			return &authChallengeError{resp.Header.Get("WWW-Authenticate")}
+		}
+		return nil
+	}))
+	var challengeErr *authChallengeError
+	if assert.True(t, errors.As(err, &challengeErr)) {
+		assert.Equal(t, `Bearer realm="stream"`, challengeErr.challenge)
+	}
+}
+
+func TestEventSourceWithReconnectHandler(t *testing.T) {
+	server, requests := newReconnectingServer(1)
+	defer server.Close()
+
+	var infos []ReconnectInfo
+	es, err := NewEventSource(server.URL+"?token=initial", WithReconnectHandler(func(req *http.Request, info ReconnectInfo) {
+		infos = append(infos, info)
+		req.URL.RawQuery = "token=fresh"
+	}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	go discardMessageEvents(es)
+	collectStates(es.ReadyState())
+
+	assert.Equal(t, "initial", (<-requests).URL.Query().Get("token"))
+	assert.Equal(t, "fresh", (<-requests).URL.Query().Get("token"))
+	assert.Equal(t, []ReconnectInfo{{Attempt: 0, Cause: io.EOF, Delay: time.Millisecond}}, infos)
+}
+
+func TestEventSourceReconnectHandlerCalledForEveryAttempt(t *testing.T) {
+	server, requests := newReconnectingServer(5)
+	defer server.Close()
+
+	attempts := make(chan ReconnectInfo, 10)
+	es, err := NewEventSource(server.URL, WithReconnectHandler(func(req *http.Request, info ReconnectInfo) {
+		attempts <- info
+	}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	go discardMessageEvents(es)
+	collectStates(es.ReadyState())
+
+	// Each reconnection is made by the consume goroutine of the previous
+	// connection, and must not lose its info to the one before
+	assert.Len(t, requests, 6)
+	assert.Len(t, attempts, 5)
+}
+
+func TestEventSourceWithRequestRewriter(t *testing.T) {
+	server, requests := newReconnectingServer(1)
+	defer server.Close()
+
+	errSigning := errors.New("signing failed")
+	var attempts int
+	errs := make(chan error, 10)
+	es, err := NewEventSource(server.URL, WithRequestRewriter(func(req *http.Request) error {
+		attempts++
+		if attempts == 2 {
+			return errSigning
+		}
+		req.URL.RawQuery = "signature=" + strconv.Itoa(attempts)
+		return nil
+	}), WithErrorHandler(func(err error) { errs <- err }))
+	if !assert.NoError(t, err) {
+		return
+	}
+	go discardMessageEvents(es)
+	collectStates(es.ReadyState())
+
+	assert.Equal(t, "1", (<-requests).URL.Query().Get("signature"))
+	assert.Equal(t, "3", (<-requests).URL.Query().Get("signature"))
+	assert.Len(t, requests, 0)
+	assert.Equal(t, ErrStreamClosed, <-errs)
+	err = <-errs
+	assert.True(t, hasErrorCode(err, ErrCodeNetwork))
+	assert.True(t, errors.Is(err, errSigning))
+}
+
+func TestEventSourceLazyConnect(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	es := New(server.URL)
+	defer es.Close(nil)
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+
+	assert.NoError(t, es.Connect(context.Background()))
+	assert.Equal(t, Open, es.State())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.Equal(t, ErrAlreadyStarted, es.Connect(context.Background()))
+
+	closed := New(server.URL)
+	closed.Close(nil)
+	assert.Equal(t, ErrClosed, closed.Connect(context.Background()))
+}
+
+func TestEventSourceErrorsReportsFailedReconnections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("retry: 10\n\n"))
+	}))
+
+	es, err := NewEventSource(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+	// Reconnecting now fails
+	server.Close()
+
+	var failures int
+	for failures < 3 {
+		select {
+		case err := <-es.Errors():
+			if err == ErrStreamClosed {
+				continue
+			}
+			assert.True(t, hasErrorCode(err, ErrCodeNetwork), err.Error())
+			failures++
+		case <-time.After(time.Second):
+			assert.FailNow(t, "reconnection failures were not reported")
+		}
+	}
+}
+
+func TestEventSourceWithErrorHandler(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&requests, 1) > 1 {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("retry: 1\n\n"))
+	}))
+	defer server.Close()
+
+	errs := make(chan error, 2)
+	es, err := NewEventSource(server.URL, WithErrorHandler(func(err error) { errs <- err }))
+	if !assert.NoError(t, err) {
+		return
+	}
+	collectStates(es.ReadyState())
+
+	assert.Equal(t, ErrStreamClosed, <-errs)
+	var statusErr *HTTPStatusError
+	if assert.True(t, errors.As(<-errs, &statusErr)) {
+		assert.Equal(t, http.StatusInternalServerError, statusErr.StatusCode)
+	}
+	assert.Equal(t, Closed, es.State())
+}
+
+func TestEventSourceWithStateChangeHandler(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		if atomic.AddInt32(&requests, 1) > 1 {
+			rw.WriteHeader(http.StatusNoContent)
+			return
+		}
+		rw.Write([]byte("retry: 1\n\n"))
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var changes [][2]ReadyState
+	es, err := NewEventSource(server.URL, WithStateChangeHandler(func(from, to ReadyState) {
+		mu.Lock()
+		changes = append(changes, [2]ReadyState{from, to})
+		mu.Unlock()
+	}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	collectStates(es.ReadyState())
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, [][2]ReadyState{
+		{Connecting, Open},
+		{Open, Connecting},
+		{Connecting, Open},
+		{Open, Closing},
+		{Closing, Closed},
+	}, changes)
+}
+
+func TestEventSourceWithStateChangeHandlerOnly(t *testing.T) {
+	// Enough reconnections to fill the ReadyState channel
+	server, _ := newReconnectingServer(100)
+	defer server.Close()
+
+	var opened int32
+	es, err := NewEventSource(server.URL, WithStateChangeHandler(func(from, to ReadyState) {
+		if to == Open {
+			atomic.AddInt32(&opened, 1)
+		}
+	}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	go discardMessageEvents(es)
+
+	select {
+	case <-es.Done():
+	case <-time.After(5 * time.Second):
+		assert.FailNow(t, "event source stalled while ReadyState was not consumed")
+	}
+	assert.EqualValues(t, 101, atomic.LoadInt32(&opened))
+	assert.Equal(t, ErrNoContent, es.Err())
+}
+
+func TestEventSourceGivesUpReconnecting(t *testing.T) {
+	for name, opt := range map[string]EventSourceOption{
+		"attempts": WithMaxReconnectAttempts(3),
+		"budget":   WithReconnectBudget(50 * time.Millisecond),
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("Content-Type", allowedContentType)
+			rw.Write([]byte("retry: 10\n\n"))
+		}))
+		var failures int32
+		es, err := NewEventSource(server.URL, opt, WithErrorHandler(func(err error) {
+			if hasErrorCode(err, ErrCodeNetwork) {
+				atomic.AddInt32(&failures, 1)
+			}
+		}))
+		if !assert.NoError(t, err) {
+			return
+		}
+		go func() {
+			for range es.ReadyState() {
+			}
+		}()
+		// Reconnecting now fails
+		server.Close()
+
+		select {
+		case <-es.Done():
+			assert.True(t, hasErrorCode(es.Err(), ErrCodeRetryExhausted), name)
+			assert.True(t, hasErrorCode(errors.Unwrap(es.Err()), ErrCodeNetwork), name)
+		case <-time.After(time.Second):
+			assert.FailNow(t, "event source did not give up", name)
+		}
+		if name == "attempts" {
+			assert.Equal(t, int32(3), atomic.LoadInt32(&failures))
+		}
+	}
+}
+
+func TestEventSourceReconnectBudgetAbortsHungAttempt(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			rw.Header().Set("Content-Type", allowedContentType)
+			rw.Write([]byte("retry: 1\n\n"))
+			return
+		}
+		// Reconnection attempts hang
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL, WithReconnectBudget(200*time.Millisecond))
+	if !assert.NoError(t, err) {
+		return
+	}
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+
+	select {
+	case <-es.Done():
+		assert.True(t, hasErrorCode(es.Err(), ErrCodeRetryExhausted))
+		assert.True(t, errors.Is(es.Err(), ErrConnectTimeout))
+	case <-time.After(time.Second):
+		assert.FailNow(t, "hung reconnection attempt not aborted")
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestEventSourceCloseCancelsReconnection(t *testing.T) {
+	var requests int32
+	reconnecting, canceled := make(chan struct{}), make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			rw.Header().Set("Content-Type", allowedContentType)
+			rw.Write([]byte("retry: 1\ndata: first\n\n"))
+			return
+		}
+		// The reconnection attempt hangs until canceled
+		close(reconnecting)
+		<-req.Context().Done()
+		close(canceled)
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+	go discardMessageEvents(es)
+
+	<-reconnecting
+	es.Close(nil)
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		assert.FailNow(t, "reconnection attempt not canceled by Close")
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestEventSourceCloseInterruptsReconnectDelay(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("retry: 200\ndata: first\n\n"))
+	}))
+	defer server.Close()
+
+	closed := make(chan struct{}, 1)
+	es, err := NewEventSource(server.URL, WithErrorHandler(func(err error) {
+		if err == ErrStreamClosed {
+			closed <- struct{}{}
+		}
+	}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+	go discardMessageEvents(es)
+	// Closed while waiting to reconnect
+	<-closed
+	es.Close(nil)
+
+	// The reconnection goroutine must exit right away
+	deadline := time.Now().Add(100 * time.Millisecond)
+	for time.Now().Before(deadline) && reconnecting(es) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.False(t, reconnecting(es), "reconnection goroutine leaked")
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests), "reconnected after Close")
+}
+
+// reconnecting tells whether a goroutine is running the reconnect method of es.
+func reconnecting(es *EventSource) bool {
+	buf := make([]byte, 1<<20)
+	return bytes.Contains(buf[:runtime.Stack(buf, true)], []byte(fmt.Sprintf("(*EventSource).reconnect(%p", es)))
 }
 
 func assertStates(t *testing.T, expected []ReadyState, states <-chan Status) {
@@ -212,6 +1130,25 @@ func assertStates(t *testing.T, expected []ReadyState, states <-chan Status) {
 	assert.Equal(t, expected, actual)
 }
 
+// newReconnectingServer returns a server whose first reconnects responses send an
+// event then end the stream, forcing a reconnection after 1ms, and whose next
+// responses are 204 No Content, which stops the event source.
+// A copy of every request received is sent on the returned channel.
+func newReconnectingServer(reconnects int) (*httptest.Server, <-chan *http.Request) {
+	requests := make(chan *http.Request, reconnects+16)
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests <- req.Clone(context.Background())
+		rw.Header().Set("Content-Type", allowedContentType)
+		if atomic.AddInt32(&count, 1) <= int32(reconnects) {
+			rw.Write([]byte("retry: 1\ndata: first\n\n"))
+			return
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	return server, requests
+}
+
 func collectStates(states <-chan Status) []ReadyState {
 	list := []ReadyState{}
 	poll := true
@@ -0,0 +1,44 @@
+package sse
+
+import (
+	"errors"
+	"net/http"
+)
+
+// WithFailoverURLs sets alternative URLs of the stream, e.g. in other regions.
+// When a connection attempt fails with a network error or a 5xx status code,
+// the next attempt is made to the next URL, in order, starting over with the
+// URL given to NewEventSource after the last one. The first connection fails
+// only once all the URLs were tried.
+// After a disconnection, the event source reconnects first to the URL it was
+// connected to, being the last known-good one.
+func WithFailoverURLs(urls ...string) EventSourceOption {
+	return func(es *EventSource) {
+		es.failoverURLs = urls
+	}
+}
+
+// failOver switches to the next URL if the connection attempt failed with err
+// because of the current URL. It returns false if there is no URL to switch to.
+func (es *EventSource) failOver(err error) bool {
+	if len(es.failoverURLs) == 0 {
+		return false
+	}
+	var sseErr *SSEError
+	if !errors.As(err, &sseErr) || (sseErr.Code != ErrCodeNetwork && sseErr.StatusCode < http.StatusInternalServerError) {
+		return false
+	}
+	es.stateMutex.Lock()
+	defer es.stateMutex.Unlock()
+	if es.primaryURL == "" {
+		es.primaryURL = es.url
+	}
+	es.failoverIndex = (es.failoverIndex + 1) % (len(es.failoverURLs) + 1)
+	if es.failoverIndex == 0 {
+		es.url = es.primaryURL
+	} else {
+		es.url = es.failoverURLs[es.failoverIndex-1]
+	}
+	es.logf("eventsource: failing over to %s: %v", es.url, err)
+	return true
+}
@@ -0,0 +1,54 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourceFailover(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	var unavailableRequests, healthyRequests int32
+	unavailable := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&unavailableRequests, 1)
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		if atomic.AddInt32(&healthyRequests, 1) > 1 {
+			rw.WriteHeader(http.StatusNoContent)
+			return
+		}
+		// Force a reconnection
+		rw.Write([]byte("retry: 1\ndata: first\n\n"))
+	}))
+	defer healthy.Close()
+
+	es, err := NewEventSource(down.URL, WithFailoverURLs(unavailable.URL, healthy.URL))
+	if !assert.NoError(t, err) {
+		return
+	}
+	go discardMessageEvents(es)
+	assert.Equal(t, healthy.URL, es.URL())
+	collectStates(es.ReadyState())
+
+	// The reconnection went to the last known-good URL
+	assert.Equal(t, int32(1), atomic.LoadInt32(&unavailableRequests))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&healthyRequests))
+}
+
+func TestEventSourceFailoverExhausted(t *testing.T) {
+	unavailable := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+	defer unavailable.Close()
+
+	_, err := NewEventSource(unavailable.URL+"/a", WithFailoverURLs(unavailable.URL+"/b"))
+	assert.True(t, hasErrorCode(err, ErrCodeHTTPStatus))
+}
@@ -0,0 +1,192 @@
+package sse
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"sync/atomic"
+)
+
+var (
+	// ErrSkipEvent can be returned by a FieldParser to drop the event being decoded.
+	// The remaining lines of the event are skipped and no error is reported.
+	ErrSkipEvent = errors.New("decoder: skip event")
+)
+
+type (
+	// FieldParser handles the lines of a given field, see Decoder.RegisterFieldParser.
+	FieldParser interface {
+		// FieldName returns the name of the handled field.
+		FieldName() string
+		// Parse is called with the value of every line of the field and updates
+		// the event being decoded. Errors are returned by Decoder.Decode as a
+		// *ParseError, unless ErrSkipEvent is returned.
+		Parse(value string, ev *PartialEvent) error
+	}
+
+	// PartialEvent is the event being decoded, updated by FieldParsers as the
+	// lines of the event are read.
+	// The id and retry time last set are kept across events, as specified.
+	PartialEvent struct {
+		name      string
+		id        string
+		retry     int
+		data      *bytes.Buffer
+		dataLines int
+		fields    map[string]string
+		// Whether the event must be dispatched on the next empty line
+		seen   bool
+		idSeen bool
+		// Whether the retry time was set, rather than the default one
+		retrySet bool
+	}
+
+	dataFieldParser  struct{ d *Decoder }
+	idFieldParser    struct{ d *Decoder }
+	eventFieldParser struct{}
+	retryFieldParser struct{}
+)
+
+// RegisterFieldParser makes the Decoder handle the lines of the p.FieldName() field
+// with p. It overrides the built-in handling of the standard fields (data, event,
+// id and retry) and allows handling custom ones, which are ignored otherwise.
+// It must not be called concurrently with Decode.
+func (d *Decoder) RegisterFieldParser(p FieldParser) {
+	d.parsers[p.FieldName()] = p
+}
+
+func (d *Decoder) registerBuiltinParsers() {
+	d.parsers = make(map[string]FieldParser)
+	for _, p := range []FieldParser{dataFieldParser{d}, idFieldParser{d}, eventFieldParser{}, retryFieldParser{}} {
+		d.RegisterFieldParser(p)
+	}
+}
+
+// Name returns the event name.
+func (ev *PartialEvent) Name() string {
+	return ev.name
+}
+
+// SetName sets the event name.
+func (ev *PartialEvent) SetName(name string) {
+	ev.name = name
+	ev.seen = true
+}
+
+// ID returns the last event id.
+func (ev *PartialEvent) ID() string {
+	return ev.id
+}
+
+// SetID sets the last event id.
+func (ev *PartialEvent) SetID(id string) {
+	ev.id = id
+	ev.seen = true
+	ev.idSeen = true
+}
+
+// Retry returns the reconnection time in milliseconds.
+func (ev *PartialEvent) Retry() int {
+	return ev.retry
+}
+
+// SetRetry sets the reconnection time in milliseconds, see Decoder.Retry.
+// It does not cause the event to be dispatched.
+func (ev *PartialEvent) SetRetry(retry int) {
+	ev.retry = retry
+	ev.retrySet = true
+}
+
+// Data returns the data appended so far, each line terminated with a LF.
+func (ev *PartialEvent) Data() string {
+	return ev.data.String()
+}
+
+// DataLines returns the number of data lines appended so far.
+func (ev *PartialEvent) DataLines() int {
+	return ev.dataLines
+}
+
+// AppendData appends a line of data.
+func (ev *PartialEvent) AppendData(line string) {
+	ev.data.WriteString(line)
+	ev.data.WriteByte('\n')
+	ev.dataLines++
+	ev.seen = true
+}
+
+// SetField stores a raw field, available on the decoded event through
+// MessageEvent.Fields.
+func (ev *PartialEvent) SetField(name, value string) {
+	if ev.fields == nil {
+		ev.fields = make(map[string]string)
+	}
+	ev.fields[name] = value
+	ev.seen = true
+}
+
+// reset clears the event, except for the id and retry time.
+func (ev *PartialEvent) reset() {
+	ev.name = ""
+	ev.data.Reset()
+	ev.dataLines = 0
+	ev.fields = nil
+	ev.seen, ev.idSeen = false, false
+}
+
+// event returns the MessageEvent to dispatch.
+func (ev *PartialEvent) event() *MessageEvent {
+	data := ev.data.Bytes()
+	// Trim the last LF
+	if l := len(data); l > 0 {
+		data = data[:l-1]
+	}
+	event := &MessageEvent{LastEventID: ev.id, Name: ev.name, Data: string(data)}
+	if ev.fields != nil {
+		fields := ev.fields
+		event.updateExtras(func(x *eventExtras) { x.fields = fields })
+	}
+	return event
+}
+
+func (dataFieldParser) FieldName() string { return "data" }
+
+func (p dataFieldParser) Parse(value string, ev *PartialEvent) error {
+	if p.d.maxData > 0 && ev.dataLines >= p.d.maxData {
+		atomic.AddUint64(&p.d.stats.DroppedByDataLineLimit, 1)
+		if p.d.strict {
+			return ErrTooManyDataLines
+		}
+		return ErrSkipEvent
+	}
+	ev.AppendData(value)
+	return nil
+}
+
+func (idFieldParser) FieldName() string { return "id" }
+
+func (p idFieldParser) Parse(value string, ev *PartialEvent) error {
+	if err := p.d.checkIDVersion(value); err != nil {
+		return err
+	}
+	ev.SetID(value)
+	return nil
+}
+
+func (eventFieldParser) FieldName() string { return "event" }
+
+func (eventFieldParser) Parse(value string, ev *PartialEvent) error {
+	ev.SetName(value)
+	return nil
+}
+
+func (retryFieldParser) FieldName() string { return "retry" }
+
+// Invalid values are ignored, as specified.
+func (retryFieldParser) Parse(value string, ev *PartialEvent) error {
+	retry, err := strconv.Atoi(value)
+	if err == nil && retry >= 0 {
+		ev.SetRetry(retry)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package sse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lowercaseParser stores the lowercased value of a custom field.
+type lowercaseParser struct{}
+
+func (lowercaseParser) FieldName() string { return "topic" }
+
+func (lowercaseParser) Parse(value string, ev *PartialEvent) error {
+	ev.SetField("topic", strings.ToLower(value))
+	return nil
+}
+
+// jsonDataParser only accepts data lines holding a JSON value.
+type jsonDataParser struct{}
+
+func (jsonDataParser) FieldName() string { return "data" }
+
+func (jsonDataParser) Parse(value string, ev *PartialEvent) error {
+	if value == "skip" {
+		return ErrSkipEvent
+	}
+	if !json.Valid([]byte(value)) {
+		return errors.New("data is not JSON")
+	}
+	ev.AppendData(value)
+	return nil
+}
+
+func TestDecoderCustomFieldParser(t *testing.T) {
+	decoder := NewDecoder(bytes.NewReader([]byte("topic: Quotes\ndata: 1\n\ntopic: alone\n\n")))
+	decoder.RegisterFieldParser(lowercaseParser{})
+
+	ev, err := decoder.Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1", ev.Data)
+		assert.Equal(t, map[string]string{"topic": "quotes"}, ev.Fields())
+		// Raw fields do not take part in the comparison of events
+		assert.True(t, *ev == MessageEvent{"", "", "1"})
+		// Subscriptions receive copies of the events
+		assert.Equal(t, ev.Fields(), ev.clone().Fields())
+	}
+	ev, err = decoder.Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "", ev.Data)
+		assert.Equal(t, map[string]string{"topic": "alone"}, ev.Fields())
+	}
+	_, err = decoder.Decode()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestDecoderOverrideBuiltinFieldParser(t *testing.T) {
+	decoder := NewDecoder(bytes.NewReader([]byte("data: skip\ndata: 1\n\ndata: {}\n\nid: 1\ndata: not json\n\n")))
+	decoder.RegisterFieldParser(jsonDataParser{})
+
+	ev, err := decoder.Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{}", ev.Data)
+		assert.Nil(t, ev.Fields())
+	}
+
+	_, err = decoder.Decode()
+	var parseErr *ParseError
+	if assert.True(t, errors.As(err, &parseErr)) {
+		assert.Equal(t, 7, parseErr.Line)
+		assert.Equal(t, "data", parseErr.FieldName)
+		assert.EqualError(t, parseErr.Cause, "data is not JSON")
+	}
+}
@@ -0,0 +1,196 @@
+package sse
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// IndexSuffix is appended to the path of an SSE log file to name its index.
+const IndexSuffix = ".sse.idx"
+
+var (
+	// ErrNoIndex error indicates FileDecoder.SeekToID was called without an index,
+	// see FileDecoder.BuildIndex.
+	ErrNoIndex = errors.New("decoder: no index, BuildIndex must be called first")
+	// ErrIDNotFound error indicates an id is not in the index of a FileDecoder.
+	ErrIDNotFound = errors.New("decoder: id not found in the index")
+)
+
+// FileDecoder decodes events from a file holding an archived SSE stream.
+// It can seek to a given event by id once the file is indexed.
+// A FileDecoder is not safe for concurrent use: a channel returned by Decode
+// or ReplayFrom must be drained before calling any other method.
+type FileDecoder struct {
+	path  string
+	f     *os.File
+	index map[string]int64
+	// Decodes from the current position, reset when seeking
+	d   *Decoder
+	err error
+}
+
+// NewFileDecoder opens the SSE log file at path. The index persisted by
+// BuildIndex is loaded if present.
+func NewFileDecoder(path string) (*FileDecoder, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	fd := &FileDecoder{path: path, f: f}
+	if err := fd.loadIndex(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return fd, nil
+}
+
+// Next returns the next event read from the current position, or io.EOF at the
+// end of the file. Unlike Decode, it lets the caller read at its own pace without
+// a goroutine, and returns read errors.
+func (fd *FileDecoder) Next() (*MessageEvent, error) {
+	if fd.d == nil {
+		fd.d = NewDecoder(fd.f)
+	}
+	return fd.d.Decode()
+}
+
+// Decode returns a channel of the events read sequentially from the current
+// position. The channel is closed at the end of the file, or on the first read
+// error, see Err.
+func (fd *FileDecoder) Decode() <-chan *MessageEvent {
+	out := make(chan *MessageEvent)
+	fd.err = nil
+	go func() {
+		defer close(out)
+		for {
+			ev, err := fd.Next()
+			if err != nil {
+				if err != io.EOF {
+					fd.err = err
+				}
+				return
+			}
+			out <- ev
+		}
+	}()
+	return out
+}
+
+// Err returns the error which closed the channel returned by Decode or
+// ReplayFrom, nil if the end of the file was reached.
+// It must only be called once the channel is closed.
+func (fd *FileDecoder) Err() error {
+	return fd.err
+}
+
+// SeekToOffset moves to offset bytes from the start of the file, which must be
+// the start of an event.
+func (fd *FileDecoder) SeekToOffset(offset int64) error {
+	// Data buffered by the decoder is no longer relevant
+	fd.d = nil
+	_, err := fd.f.Seek(offset, io.SeekStart)
+	return err
+}
+
+// SeekToID moves to the start of the event with the given id, so that it is the
+// next event decoded.
+func (fd *FileDecoder) SeekToID(id string) error {
+	if fd.index == nil {
+		return ErrNoIndex
+	}
+	offset, ok := fd.index[id]
+	if !ok {
+		return ErrIDNotFound
+	}
+	return fd.SeekToOffset(offset)
+}
+
+// ReplayFrom decodes the events starting with the event with the given id.
+// If the id cannot be found, the returned channel is closed right away, and Err
+// returns ErrIDNotFound.
+func (fd *FileDecoder) ReplayFrom(id string) <-chan *MessageEvent {
+	if err := fd.SeekToID(id); err != nil {
+		fd.err = err
+		out := make(chan *MessageEvent)
+		close(out)
+		return out
+	}
+	return fd.Decode()
+}
+
+// BuildIndex scans the whole file once to map every event id to the offset of
+// its event, and persists the index next to the file with the IndexSuffix.
+// The position in the file is reset to the start.
+func (fd *FileDecoder) BuildIndex() error {
+	if err := fd.SeekToOffset(0); err != nil {
+		return err
+	}
+	index := make(map[string]int64)
+	var offset, blockStart int64
+	var id string
+	var inBlock, idSeen bool
+
+	scanner := bufio.NewScanner(fd.f)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := scanLinesCR(data, atEOF)
+		offset += int64(advance)
+		return advance, token, err
+	})
+	var lineStart int64
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case len(line) == 0:
+			if idSeen {
+				index[id] = blockStart
+			}
+			inBlock, idSeen = false, false
+		case !inBlock:
+			inBlock, blockStart = true, lineStart
+			fallthrough
+		default:
+			if line == "id" || strings.HasPrefix(line, "id:") {
+				id = strings.TrimPrefix(strings.TrimPrefix(line, "id"), ":")
+				id = strings.TrimPrefix(id, " ")
+				idSeen = true
+			}
+		}
+		lineStart = offset
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if err := fd.saveIndex(index); err != nil {
+		return err
+	}
+	fd.index = index
+	return fd.SeekToOffset(0)
+}
+
+// Close closes the file.
+func (fd *FileDecoder) Close() error {
+	return fd.f.Close()
+}
+
+func (fd *FileDecoder) loadIndex() error {
+	data, err := os.ReadFile(fd.path + IndexSuffix)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &fd.index)
+}
+
+func (fd *FileDecoder) saveIndex(index map[string]int64) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fd.path+IndexSuffix, data, 0644)
+}
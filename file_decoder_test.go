@@ -0,0 +1,81 @@
+package sse
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileDecoderSeekToID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stream.sse")
+	var log strings.Builder
+	log.WriteString(": archived stream\n\n")
+	for i := 1; i <= 10000; i++ {
+		fmt.Fprintf(&log, "event: tick\r\nid: %d\r\ndata: event %d\r\ndata: second line\r\n\r\n", i, i)
+	}
+	if !assert.NoError(t, os.WriteFile(path, []byte(log.String()), 0644)) {
+		return
+	}
+
+	fd, err := NewFileDecoder(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, ErrNoIndex, fd.SeekToID("5000"))
+	if !assert.NoError(t, fd.BuildIndex()) {
+		return
+	}
+	fd.Close()
+
+	// The index is loaded from its sidecar file
+	assert.FileExists(t, path+IndexSuffix)
+	fd, err = NewFileDecoder(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer fd.Close()
+
+	assert.Equal(t, ErrIDNotFound, fd.SeekToID("10001"))
+	if assert.NoError(t, fd.SeekToID("5000")) {
+		events := collectFileEvents(fd.Decode())
+		if assert.Len(t, events, 5001) {
+			assert.Equal(t, &MessageEvent{LastEventID: "5000", Name: "tick", Data: "event 5000\nsecond line"}, events[0])
+			assert.Equal(t, "10000", events[5000].LastEventID)
+		}
+	}
+
+	events := collectFileEvents(fd.ReplayFrom("9999"))
+	assert.Equal(t, []string{"9999", "10000"}, []string{events[0].LastEventID, events[1].LastEventID})
+	assert.NoError(t, fd.Err())
+	assert.Empty(t, collectFileEvents(fd.ReplayFrom("unknown")))
+	assert.Equal(t, ErrIDNotFound, fd.Err())
+
+	if assert.NoError(t, fd.SeekToOffset(0)) {
+		assert.Len(t, collectFileEvents(fd.Decode()), 10000)
+	}
+
+	// Next reads at the pace of the caller, and is reset by seeking
+	if assert.NoError(t, fd.SeekToID("9999")) {
+		ev, err := fd.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, "9999", ev.LastEventID)
+		ev, err = fd.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, "10000", ev.LastEventID)
+		_, err = fd.Next()
+		assert.Equal(t, io.EOF, err)
+	}
+}
+
+func collectFileEvents(events <-chan *MessageEvent) []*MessageEvent {
+	var list []*MessageEvent
+	for ev := range events {
+		list = append(list, ev)
+	}
+	return list
+}
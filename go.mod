@@ -1,8 +1,23 @@
 module github.com/go-rfc/sse
 
-go 1.15
+go 1.19
 
 require (
+	github.com/andybalholm/brotli v1.0.5
+	github.com/gorilla/websocket v1.5.0
+	github.com/klauspost/compress v1.16.0
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/stretchr/testify v1.6.1
+	golang.org/x/oauth2 v0.6.0
+	golang.org/x/time v0.3.0
+	google.golang.org/protobuf v1.28.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
+	golang.org/x/net v0.8.0 // indirect
 	golang.org/x/tools v0.0.0-20200925180533-e8435508c66b // indirect
+	google.golang.org/appengine v1.6.7 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
 )
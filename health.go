@@ -0,0 +1,85 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Time given to in-flight health requests to complete once the event source is closed.
+const healthShutdownTimeout = 5 * time.Second
+
+// healthDocument is served by the health endpoint.
+type healthDocument struct {
+	State             ReadyState `json:"state"`
+	URL               string     `json:"url"`
+	LastEventID       string     `json:"last_event_id"`
+	ConnectAttempts   uint64     `json:"connect_attempts"`
+	BytesReceived     uint64     `json:"bytes_received"`
+	LastReconnectTime *time.Time `json:"last_reconnect_time"`
+}
+
+// WithHealthEndpoint serves a JSON health document describing the event source
+// on addr at the given path. The response status is 200 OK while the event
+// source is open, 503 Service Unavailable otherwise.
+// The health server is shut down when the event source is closed.
+func WithHealthEndpoint(addr, path string) EventSourceOption {
+	return func(es *EventSource) {
+		es.healthAddr = addr
+		es.healthPath = path
+	}
+}
+
+func (es *EventSource) startHealthServer() error {
+	if es.healthAddr == "" {
+		return nil
+	}
+	ln, err := net.Listen("tcp", es.healthAddr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(es.healthPath, es.serveHealth)
+	es.healthServer = &http.Server{Handler: mux}
+	go func() {
+		if err := es.healthServer.Serve(ln); err != http.ErrServerClosed {
+			es.logf("eventsource: health endpoint stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (es *EventSource) stopHealthServer() {
+	if es.healthServer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), healthShutdownTimeout)
+	defer cancel()
+	es.healthServer.Shutdown(ctx)
+}
+
+func (es *EventSource) serveHealth(rw http.ResponseWriter, req *http.Request) {
+	state := es.State()
+	es.stateMutex.RLock()
+	doc := healthDocument{
+		State:           state,
+		URL:             es.url,
+		LastEventID:     es.lastEventID,
+		ConnectAttempts: atomic.LoadUint64(&es.connectAttempts),
+		BytesReceived:   atomic.LoadUint64(&es.bytesReceived),
+	}
+	if !es.lastReconnect.IsZero() {
+		lastReconnect := es.lastReconnect
+		doc.LastReconnectTime = &lastReconnect
+	}
+	es.stateMutex.RUnlock()
+
+	rw.Header().Set("Content-Type", "application/json")
+	if state != Open {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(rw).Encode(doc)
+}
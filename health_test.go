@@ -0,0 +1,67 @@
+package sse
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/go-rfc/sse/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthEndpoint(t *testing.T) {
+	runTest(t, func(handler *testutils.TestServerHandler) {
+		addr := freeAddr(t)
+		es, err := NewEventSource(handler.URL, WithHealthEndpoint(addr, "/health"))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		go handler.SendWithID(newMessageEventString("1", "", 16), "1")
+		<-es.MessageEvents()
+		assert.Equal(t, Open, es.State())
+
+		resp, err := http.Get("http://" + addr + "/health")
+		if assert.NoError(t, err) {
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var doc healthDocument
+			if assert.NoError(t, json.NewDecoder(resp.Body).Decode(&doc)) {
+				assert.Equal(t, Open, doc.State)
+				assert.Equal(t, handler.URL, doc.URL)
+				assert.Equal(t, "1", doc.LastEventID)
+				assert.Equal(t, uint64(1), doc.ConnectAttempts)
+				assert.True(t, doc.BytesReceived > 0)
+				assert.Nil(t, doc.LastReconnectTime)
+			}
+		}
+
+		// The health server is shut down with the event source
+		es.Close(nil)
+		assert.Equal(t, Closed, es.State())
+		_, err = http.Get("http://" + addr + "/health")
+		assert.Error(t, err)
+	})
+}
+
+func TestHealthEndpointAddressInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer ln.Close()
+
+	_, err = NewEventSource("http://127.0.0.1:1", WithHealthEndpoint(ln.Addr().String(), "/health"))
+	assert.Error(t, err)
+}
+
+func freeAddr(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
@@ -0,0 +1,63 @@
+package sse
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// TimestampHeader carries the timestamp of the requests signed with WithHMACSigning.
+const TimestampHeader = "X-Timestamp"
+
+var (
+	// ErrHMACAlgorithm error indicates WithHMACSigning was given an unsupported algorithm.
+	ErrHMACAlgorithm = errors.New("eventsource: unsupported HMAC algorithm")
+)
+
+// Supported HMAC algorithms, by name
+var hmacAlgorithms = map[string]func() hash.Hash{
+	"HMAC-SHA256": sha256.New,
+	"HMAC-SHA512": sha512.New,
+}
+
+// WithHMACSigning signs every request, including reconnections, with key.
+// The algorithm is either HMAC-SHA256 or HMAC-SHA512; connecting fails with
+// ErrHMACAlgorithm otherwise.
+//
+// The signature is computed over the canonical request
+//
+//	<method>\n<url>\n<timestamp>\n<last event id>
+//
+// where timestamp is the Unix time of the request, in seconds, also sent in
+// the TimestampHeader. It is sent hex encoded in the Authorization header:
+//
+//	Authorization: HMAC-SHA256 sig=<signature>
+func WithHMACSigning(key []byte, algorithm string) EventSourceOption {
+	return func(es *EventSource) {
+		es.hmacKey = key
+		es.hmacAlgorithm = algorithm
+	}
+}
+
+// sign req with the HMAC key, if any.
+func (es *EventSource) sign(req *http.Request) error {
+	if es.hmacAlgorithm == "" {
+		return nil
+	}
+	newHash, ok := hmacAlgorithms[es.hmacAlgorithm]
+	if !ok {
+		return &SSEError{Code: ErrCodeNetwork, Message: es.hmacAlgorithm, Cause: ErrHMACAlgorithm}
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(TimestampHeader, timestamp)
+	mac := hmac.New(newHash, es.hmacKey)
+	mac.Write([]byte(req.Method + "\n" + req.URL.String() + "\n" + timestamp + "\n" + req.Header.Get("Last-Event-ID")))
+	req.Header.Set("Authorization", es.hmacAlgorithm+" sig="+hex.EncodeToString(mac.Sum(nil)))
+	return nil
+}
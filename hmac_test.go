@@ -0,0 +1,60 @@
+package sse
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourceHMACSigning(t *testing.T) {
+	key := []byte("secret")
+	verified := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		lastEventID := req.Header.Get("Last-Event-ID")
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte("GET\nhttp://" + req.Host + req.URL.String() + "\n" + req.Header.Get(TimestampHeader) + "\n" + lastEventID))
+		expected := "HMAC-SHA256 sig=" + hex.EncodeToString(mac.Sum(nil))
+		if req.Header.Get("Authorization") != expected {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		verified <- lastEventID
+		rw.Header().Set("Content-Type", allowedContentType)
+		if lastEventID == "" {
+			// Force a reconnection
+			rw.Write([]byte("retry: 1\nid: 1\ndata: first\n\n"))
+			return
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL+"/stream?topic=a", WithHMACSigning(key, "HMAC-SHA256"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	go discardMessageEvents(es)
+	collectStates(es.ReadyState())
+
+	for _, expected := range []string{"", "1"} {
+		select {
+		case lastEventID := <-verified:
+			assert.Equal(t, expected, lastEventID)
+		case <-time.After(time.Second):
+			assert.FailNow(t, "request signature not verified")
+		}
+	}
+}
+
+func TestEventSourceHMACSigningUnsupportedAlgorithm(t *testing.T) {
+	_, err := NewEventSource("http://localhost", WithHMACSigning([]byte("secret"), "HMAC-MD5"))
+	assert.True(t, errors.Is(err, ErrHMACAlgorithm))
+	assert.Contains(t, err.Error(), "HMAC-MD5")
+}
@@ -0,0 +1,150 @@
+package sse
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+type (
+	// IDStore persists the last event id of an EventSource, see WithIDStore.
+	IDStore interface {
+		// Load returns the last saved id, or an empty string if none was saved.
+		Load() (string, error)
+		// Save replaces the saved id.
+		Save(id string) error
+	}
+
+	// FileIDStore is an IDStore keeping the id in a file.
+	FileIDStore struct {
+		path string
+	}
+
+	// idSaver saves the ids of an EventSource in the background, so that a slow
+	// IDStore does not delay the dispatch of events. Only the latest id is kept
+	// while a save is in progress.
+	idSaver struct {
+		mu      sync.Mutex
+		id      string
+		pending bool
+		wake    chan struct{}
+	}
+)
+
+// WithIDStore loads the Last-Event-ID header of the first connection from store,
+// and saves the id of the received events to it every time it changes, so that
+// the event source resumes from where it stopped after a process restart.
+// Connecting fails if the id cannot be loaded. Save failures are reported on
+// the Errors channel.
+// Ids are saved in the background and only the latest one is saved when the
+// store is slower than the stream, hence after a crash the store may hold an
+// earlier id than the last received event, whose followers are received again.
+// The last id received before the event source is closed is saved once closed.
+func WithIDStore(store IDStore) EventSourceOption {
+	return func(es *EventSource) {
+		es.idStore = store
+	}
+}
+
+// NewFileIDStore returns an IDStore keeping the id in the file at path.
+// The file is created on the first save.
+func NewFileIDStore(path string) *FileIDStore {
+	return &FileIDStore{path: path}
+}
+
+// Load returns the id saved in the file, or an empty string if it does not exist.
+func (s *FileIDStore) Load() (string, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return strings.TrimSuffix(string(data), "\n"), err
+}
+
+// Save replaces the id saved in the file. The file is replaced atomically, so
+// that a crash while saving does not lose the previous id.
+func (s *FileIDStore) Save(id string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.WriteString(id + "\n"); err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// loadLastEventID seeds the last event id from the id store, if any.
+func (es *EventSource) loadLastEventID() error {
+	if es.idStore == nil {
+		return nil
+	}
+	id, err := es.idStore.Load()
+	if err != nil || id == "" {
+		return err
+	}
+	es.stateMutex.Lock()
+	es.lastEventID = id
+	es.stateMutex.Unlock()
+	return nil
+}
+
+// startIDSaver starts saving ids to the id store, if any.
+func (es *EventSource) startIDSaver() {
+	if es.idStore == nil {
+		return
+	}
+	es.idSaver = &idSaver{wake: make(chan struct{}, 1)}
+	go es.runIDSaver()
+}
+
+// saveLastEventID schedules saving id to the id store, if any.
+func (es *EventSource) saveLastEventID(id string) {
+	s := es.idSaver
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.id, s.pending = id, true
+	s.mu.Unlock()
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (es *EventSource) runIDSaver() {
+	for {
+		select {
+		case <-es.idSaver.wake:
+			es.flushLastEventID()
+		case <-es.done:
+			es.flushLastEventID()
+			return
+		}
+	}
+}
+
+// flushLastEventID saves the latest scheduled id, if not saved yet.
+func (es *EventSource) flushLastEventID() {
+	s := es.idSaver
+	s.mu.Lock()
+	id, pending := s.id, s.pending
+	s.pending = false
+	s.mu.Unlock()
+	if !pending {
+		return
+	}
+	if err := es.idStore.Save(id); err != nil {
+		es.notifyError(err)
+	}
+}
@@ -0,0 +1,142 @@
+package sse
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileIDStore(t *testing.T) {
+	store := NewFileIDStore(filepath.Join(t.TempDir(), "last-event-id"))
+	id, err := store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "", id)
+
+	assert.NoError(t, store.Save("1"))
+	assert.NoError(t, store.Save("2"))
+	id, err = store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "2", id)
+}
+
+func TestEventSourceWithIDStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Last-Event-ID") != "5" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("id: 6\ndata: a\n\ndata: b\n\n"))
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "last-event-id")
+	assert.NoError(t, os.WriteFile(path, []byte("5\n"), 0644))
+	es, err := NewEventSource(server.URL, WithIDStore(NewFileIDStore(path)))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+
+	<-es.MessageEvents()
+	<-es.MessageEvents()
+	// Ids are saved in the background
+	for i := 0; i < 100; i++ {
+		if data, _ := os.ReadFile(path); string(data) == "6\n" {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Fail(t, "the id was not saved")
+}
+
+// slowIDStore takes delay to save every id.
+type slowIDStore struct {
+	delay time.Duration
+	mu    sync.Mutex
+	saved []string
+}
+
+func (s *slowIDStore) Load() (string, error) { return "", nil }
+
+func (s *slowIDStore) Save(id string) error {
+	time.Sleep(s.delay)
+	s.mu.Lock()
+	s.saved = append(s.saved, id)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *slowIDStore) savedIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.saved...)
+}
+
+func TestEventSourceWithSlowIDStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		for i := 1; i <= 10; i++ {
+			fmt.Fprintf(rw, "id: %d\ndata: %d\n\n", i, i)
+		}
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	store := &slowIDStore{delay: 100 * time.Millisecond}
+	es, err := NewEventSource(server.URL, WithIDStore(store))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+
+	// Saves do not delay the events
+	timeout := time.After(500 * time.Millisecond)
+	for i := 0; i < 10; i++ {
+		select {
+		case <-es.MessageEvents():
+		case <-timeout:
+			assert.FailNow(t, "the id store delayed the events")
+		}
+	}
+
+	// Only the latest id is saved once the pending save is done
+	es.Close(nil)
+	for i := 0; i < 100; i++ {
+		if saved := store.savedIDs(); len(saved) > 0 && saved[len(saved)-1] == "10" {
+			assert.Less(t, len(saved), 10)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Fail(t, "the last id was not saved", "saved %v", store.savedIDs())
+}
+
+type failingIDStore struct{}
+
+func (failingIDStore) Load() (string, error) { return "", errors.New("unavailable") }
+func (failingIDStore) Save(string) error     { return nil }
+
+func TestEventSourceWithFailingIDStore(t *testing.T) {
+	_, err := NewEventSource("http://localhost", WithIDStore(failingIDStore{}))
+	assert.EqualError(t, err, "unavailable")
+}
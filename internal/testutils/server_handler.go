@@ -3,6 +3,7 @@ package testutils
 import (
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -26,6 +27,7 @@ type TestServerHandler struct {
 	MaxRequestsToProcess int
 
 	t           *testing.T
+	mu          sync.Mutex
 	lastEventID string
 	events      chan string
 	closer      chan struct{}
@@ -36,7 +38,10 @@ func (h *TestServerHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request)
 	rw.Header().Set("Content-Type", h.ContentType)
 
 	// Assert EventSource follows the spec and provides the Last-Event-ID header.
-	if !assert.Equal(h.t, h.lastEventID, req.Header.Get("Last-Event-ID"), "spec violation: eventsource reconnected without providing the last event id.") {
+	h.mu.Lock()
+	lastEventID := h.lastEventID
+	h.mu.Unlock()
+	if !assert.Equal(h.t, lastEventID, req.Header.Get("Last-Event-ID"), "spec violation: eventsource reconnected without providing the last event id.") {
 		rw.WriteHeader(http.StatusNoContent)
 		return
 	}
@@ -69,7 +74,9 @@ func (h *TestServerHandler) Send(data string) {
 
 func (h *TestServerHandler) SendWithID(data, lastEventID string) {
 	h.events <- data
+	h.mu.Lock()
 	h.lastEventID = lastEventID
+	h.mu.Unlock()
 }
 
 func (h *TestServerHandler) SendAndClose(data string) {
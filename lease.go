@@ -0,0 +1,71 @@
+package sse
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// WithLease renews a leased subscription by sending a POST request to renewURL
+// every interval, with a body returned by body, which may be nil.
+// Failed renewals are reported on Errors, the stream keeps being consumed until
+// the server closes it. Renewals stop once the event source is closed.
+func WithLease(renewURL string, interval time.Duration, body func() io.Reader) EventSourceOption {
+	return func(es *EventSource) {
+		es.leaseURL = renewURL
+		es.leaseInterval = interval
+		es.leaseBody = body
+	}
+}
+
+// startLease starts renewing the lease in the background, if any.
+func (es *EventSource) startLease() {
+	if es.leaseURL == "" || es.leaseInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(es.leaseInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := es.renewLease(); err != nil {
+					es.logf("eventsource: lease renewal to %s failed: %v", es.leaseURL, err)
+					es.notifyError(err)
+				}
+			case <-es.closing:
+				return
+			}
+		}
+	}()
+}
+
+func (es *EventSource) renewLease() error {
+	var body io.Reader
+	if es.leaseBody != nil {
+		body = es.leaseBody()
+	}
+	req, err := http.NewRequestWithContext(es.ctx, http.MethodPost, es.leaseURL, body)
+	if err != nil {
+		return &SSEError{Code: ErrCodeNetwork, Message: "cannot create lease renewal request", Cause: err}
+	}
+	// Renewals carry the same headers and credentials as the stream
+	if err = es.prepareRequest(req, false); err != nil {
+		return err
+	}
+	resp, err := es.client.Do(req)
+	if err != nil {
+		return &SSEError{Code: ErrCodeNetwork, Message: "cannot renew lease", Cause: err}
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &SSEError{
+			Code:       ErrCodeHTTPStatus,
+			StatusCode: resp.StatusCode,
+			Message:    "cannot renew lease",
+			Cause:      &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, URL: es.leaseURL},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package sse
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourceLeaseRenewal(t *testing.T) {
+	stream := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer stream.Close()
+
+	var renewals int32
+	renew := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		if req.Method != http.MethodPost || string(body) != "lease-1" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if atomic.AddInt32(&renewals, 1) == 2 {
+			rw.WriteHeader(http.StatusGone)
+		}
+	}))
+	defer renew.Close()
+
+	es, err := NewEventSource(stream.URL, WithLease(renew.URL, 20*time.Millisecond, func() io.Reader {
+		return strings.NewReader("lease-1")
+	}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+
+	select {
+	case err := <-es.Errors():
+		var statusErr *HTTPStatusError
+		if assert.True(t, errors.As(err, &statusErr)) {
+			assert.Equal(t, http.StatusGone, statusErr.StatusCode)
+		}
+	case <-time.After(time.Second):
+		assert.FailNow(t, "failed renewal was not reported")
+	}
+	// The stream survives the failed renewal
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, Open, es.State())
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&renewals), int32(3))
+
+	es.Close(nil)
+	time.Sleep(30 * time.Millisecond)
+	count := atomic.LoadInt32(&renewals)
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, count, atomic.LoadInt32(&renewals), "renewals continued after close")
+}
+
+func TestEventSourceLeaseRenewalIsPreparedLikeTheStream(t *testing.T) {
+	stream := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer stream.Close()
+
+	headers := make(chan http.Header, 1)
+	cancelled := make(chan struct{}, 1)
+	renew := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		headers <- req.Header
+		// Pending until the event source is closed
+		select {
+		case <-req.Context().Done():
+			cancelled <- struct{}{}
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer renew.Close()
+
+	es, err := NewEventSource(stream.URL, WithHeader("X-Tenant", "acme"), WithLease(renew.URL, 20*time.Millisecond, nil))
+	if !assert.NoError(t, err) {
+		return
+	}
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+
+	select {
+	case header := <-headers:
+		assert.Equal(t, "acme", header.Get("X-Tenant"))
+	case <-time.After(time.Second):
+		assert.FailNow(t, "lease was not renewed")
+	}
+
+	// Closing cancels the pending renewal
+	es.Close(nil)
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		assert.FailNow(t, "pending renewal was not cancelled")
+	}
+}
@@ -0,0 +1,49 @@
+package sse
+
+// ListenerID identifies a listener added with On or Once.
+type ListenerID uint64
+
+// On calls fn with every event named name, until the listener is removed with
+// RemoveListener or the event source is closed.
+// Each listener is called from its own goroutine, in the order of the events.
+// Like Subscribe, events are not sent on MessageEvents while a listener exists.
+func (es *EventSource) On(name string, fn func(*MessageEvent)) ListenerID {
+	return es.listen(name, fn, false)
+}
+
+// Once calls fn with the next event named name, then removes the listener.
+func (es *EventSource) Once(name string, fn func(*MessageEvent)) ListenerID {
+	return es.listen(name, fn, true)
+}
+
+// RemoveListener stops calling the listener id. A call in progress is not interrupted.
+// Removing an unknown or already removed listener does nothing.
+func (es *EventSource) RemoveListener(id ListenerID) {
+	es.subsMutex.Lock()
+	cancel, ok := es.listeners[id]
+	delete(es.listeners, id)
+	es.subsMutex.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+func (es *EventSource) listen(name string, fn func(*MessageEvent), once bool) ListenerID {
+	events, cancel := es.Subscribe(name)
+	es.subsMutex.Lock()
+	es.lastListener++
+	id := es.lastListener
+	es.listeners[id] = cancel
+	es.subsMutex.Unlock()
+
+	go func() {
+		defer es.RemoveListener(id)
+		for ev := range events {
+			fn(ev)
+			if once {
+				return
+			}
+		}
+	}()
+	return id
+}
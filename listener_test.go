@@ -0,0 +1,62 @@
+package sse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-rfc/sse/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListeners(t *testing.T) {
+	runTest(t, func(handler *testutils.TestServerHandler) {
+		es, err := NewEventSource(handler.URL)
+		assert.Nil(t, err)
+		defer es.Close(nil)
+		go discardMessageEvents(es)
+
+		updates := make(chan string, 10)
+		once := make(chan string, 10)
+		id := es.On("update", func(ev *MessageEvent) { updates <- ev.Data })
+		es.Once("update", func(ev *MessageEvent) { once <- ev.Data })
+
+		go handler.Send("event: update\ndata: 1\n\nevent: other\ndata: 2\n\nevent: update\ndata: 3\n\n")
+		for _, expected := range []string{"1", "3"} {
+			select {
+			case data := <-updates:
+				assert.Equal(t, expected, data)
+			case <-time.After(time.Second):
+				assert.FailNow(t, "listener not called")
+			}
+		}
+		assert.Equal(t, "1", <-once)
+
+		es.RemoveListener(id)
+		es.RemoveListener(id)
+		go handler.Send("event: update\ndata: 4\n\n")
+		time.Sleep(50 * time.Millisecond)
+		assert.Empty(t, updates)
+		assert.Empty(t, once)
+	})
+}
+
+func TestListenersWithoutReadingMessageEvents(t *testing.T) {
+	runTest(t, func(handler *testutils.TestServerHandler) {
+		es, err := NewEventSource(handler.URL)
+		assert.Nil(t, err)
+		defer es.Close(nil)
+
+		updates := make(chan string, 10)
+		es.On("update", func(ev *MessageEvent) { updates <- ev.Data })
+
+		go handler.Send("event: update\ndata: 1\n\nevent: other\ndata: 2\n\nevent: update\ndata: 3\n\n")
+		for _, expected := range []string{"1", "3"} {
+			select {
+			case data := <-updates:
+				assert.Equal(t, expected, data)
+			case <-time.After(time.Second):
+				assert.FailNow(t, "unread MessageEvents blocked the listener")
+			}
+		}
+	})
+}
@@ -0,0 +1,253 @@
+package sse
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// SourceField is the raw field holding the URL an event was received from
+// on a MergedEventSource. See MessageEvent.Fields.
+const SourceField = "_source"
+
+var (
+	// ErrNoURL error indicates a MergedEventSource was created without any URL.
+	ErrNoURL = errors.New("eventsource: no URL to connect to")
+)
+
+type (
+	// MergedEventSource connects to several event sources and delivers all
+	// their events on a single channel.
+	// When the consumer lags behind, pending events are delivered by decreasing
+	// priority of their source (see SetSourcePriority), sources of equal priority
+	// taking turns.
+	MergedEventSource struct {
+		urls      []string
+		opts      []EventSourceOption
+		out       chan *MessageEvent
+		done      chan struct{}
+		ctx       context.Context
+		cancel    context.CancelFunc
+		closeOnce sync.Once
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		sources   map[string]*EventSource
+
+		// Dispatch queue, guarded by queueMu
+		queueMu    sync.Mutex
+		queue      mergedQueue
+		notify     chan struct{}
+		priorities map[string]int
+		rounds     map[string]uint64
+		round      uint64
+		seq        uint64
+	}
+)
+
+// NewMergedEventSource connects to all the urls concurrently, applying opts to
+// each of them. Every received event is tagged with its source URL in its
+// SourceField raw field.
+// Sources are independent: a source failing to connect is logged and retried
+// without affecting the others nor closing the merged channel, using the logger
+// set with WithLogger.
+func NewMergedEventSource(urls []string, opts ...EventSourceOption) (*MergedEventSource, error) {
+	if len(urls) == 0 {
+		return nil, ErrNoURL
+	}
+
+	m := &MergedEventSource{
+		urls:    urls,
+		opts:    opts,
+		out:     make(chan *MessageEvent),
+		done:    make(chan struct{}),
+		sources: make(map[string]*EventSource),
+
+		notify:     make(chan struct{}, 1),
+		priorities: make(map[string]int),
+		rounds:     make(map[string]uint64),
+	}
+	// Canceled by Close, so that no connection attempt outlives the merged source
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	m.wg.Add(1)
+	go m.dispatch()
+	for _, url := range urls {
+		m.wg.Add(1)
+		go m.run(url)
+	}
+	return m, nil
+}
+
+// URLs returns the URLs of the merged event sources.
+func (m *MergedEventSource) URLs() []string {
+	return m.urls
+}
+
+// MessageEvents returns a channel of the events received from all the sources.
+func (m *MergedEventSource) MessageEvents() <-chan *MessageEvent {
+	return m.out
+}
+
+// SetSourcePriority sets the priority of the source connected to url, from
+// MinSourcePriority to MaxSourcePriority. Out of range values are clamped.
+// Sources have the DefaultSourcePriority unless set otherwise.
+// The new priority also applies to the events of the source already waiting
+// to be delivered.
+func (m *MergedEventSource) SetSourcePriority(url string, priority int) {
+	if priority < MinSourcePriority {
+		priority = MinSourcePriority
+	} else if priority > MaxSourcePriority {
+		priority = MaxSourcePriority
+	}
+
+	m.queueMu.Lock()
+	m.priorities[url] = priority
+	for _, item := range m.queue {
+		if item.url == url {
+			item.priority = priority
+		}
+	}
+	heap.Init(&m.queue)
+	m.queueMu.Unlock()
+	m.wakeDispatcher()
+}
+
+// Close all the underlying event sources and the events channel.
+func (m *MergedEventSource) Close() {
+	m.closeOnce.Do(func() {
+		close(m.done)
+		m.cancel()
+		m.mu.Lock()
+		for _, es := range m.sources {
+			es.Close(nil)
+		}
+		m.mu.Unlock()
+		m.wg.Wait()
+		close(m.out)
+	})
+}
+
+// run keeps an event source connected to url until the merged source is closed.
+func (m *MergedEventSource) run(url string) {
+	defer m.wg.Done()
+	for {
+		es, err := NewEventSourceWithContext(m.ctx, url, m.opts...)
+		if err != nil {
+			es.logf("eventsource: connection to %s failed: %v", url, err)
+		} else if m.register(url, es) {
+			m.forward(url, es)
+			es.logf("eventsource: connection to %s closed", url)
+		}
+
+		select {
+		case <-m.done:
+			return
+		case <-time.After(defaultRetry * time.Millisecond):
+		}
+	}
+}
+
+// register returns false if the merged source was closed meanwhile.
+func (m *MergedEventSource) register(url string, es *EventSource) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	select {
+	case <-m.done:
+		es.Close(nil)
+		return false
+	default:
+		m.sources[url] = es
+		return true
+	}
+}
+
+// forward the events of es until either es or the merged source is closed.
+func (m *MergedEventSource) forward(url string, es *EventSource) {
+	defer es.Close(nil)
+	slot := make(chan struct{}, mergedQueueSize)
+	for {
+		select {
+		case ev, ok := <-es.MessageEvents():
+			if !ok {
+				return
+			}
+			ev.setField(SourceField, url)
+			select {
+			case slot <- struct{}{}:
+				m.enqueue(url, ev, slot)
+			case <-m.done:
+				return
+			}
+		case <-es.ReadyState():
+			// Nobody else observes the ready state of es
+		case <-m.done:
+			return
+		}
+	}
+}
+
+// enqueue adds ev received from url to the dispatch queue.
+func (m *MergedEventSource) enqueue(url string, ev *MessageEvent, slot chan struct{}) {
+	m.queueMu.Lock()
+	priority, ok := m.priorities[url]
+	if !ok {
+		priority = DefaultSourcePriority
+	}
+	// A source idle for a while joins the current round rather than
+	// catching up on the rounds it missed.
+	round := m.rounds[url]
+	if round < m.round {
+		round = m.round
+	}
+	m.rounds[url] = round + 1
+	m.seq++
+	heap.Push(&m.queue, &mergedItem{ev: ev, url: url, priority: priority, round: round, seq: m.seq, slot: slot})
+	m.queueMu.Unlock()
+	m.wakeDispatcher()
+}
+
+func (m *MergedEventSource) wakeDispatcher() {
+	select {
+	case m.notify <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch delivers the queued events until the merged source is closed.
+// The head of the queue is only removed once delivered, so an event queued
+// meanwhile with a higher priority takes precedence.
+func (m *MergedEventSource) dispatch() {
+	defer m.wg.Done()
+	for {
+		m.queueMu.Lock()
+		var head *mergedItem
+		if len(m.queue) > 0 {
+			head = m.queue[0]
+		}
+		m.queueMu.Unlock()
+
+		if head == nil {
+			select {
+			case <-m.notify:
+			case <-m.done:
+				return
+			}
+			continue
+		}
+
+		select {
+		case m.out <- head.ev:
+			m.queueMu.Lock()
+			heap.Remove(&m.queue, head.index)
+			if head.round > m.round {
+				m.round = head.round
+			}
+			m.queueMu.Unlock()
+			<-head.slot
+		case <-m.notify:
+		case <-m.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package sse
+
+// Bounds of the priorities of the sources of a MergedEventSource.
+const (
+	MinSourcePriority     = 0
+	MaxSourcePriority     = 100
+	DefaultSourcePriority = 50
+)
+
+// Maximum number of events of a single connection waiting to be dispatched
+// by a MergedEventSource. Reading from a source pauses once it is reached.
+const mergedQueueSize = 16
+
+type (
+	// mergedItem is an event waiting in the dispatch queue of a MergedEventSource.
+	mergedItem struct {
+		ev       *MessageEvent
+		url      string
+		priority int
+		// round of the source the event belongs to, equal priority sources
+		// are served one event per round.
+		round uint64
+		seq   uint64
+		// slot is released once the event is dispatched
+		slot  chan struct{}
+		index int
+	}
+
+	// mergedQueue implements heap.Interface, higher priorities first.
+	mergedQueue []*mergedItem
+)
+
+func (q mergedQueue) Len() int { return len(q) }
+
+func (q mergedQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	if q[i].round != q[j].round {
+		return q[i].round < q[j].round
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q mergedQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *mergedQueue) Push(x interface{}) {
+	item := x.(*mergedItem)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+
+func (q *mergedQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*q = old[:n-1]
+	return item
+}
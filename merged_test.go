@@ -0,0 +1,219 @@
+package sse
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-rfc/sse/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergedEventSourceTagsEventsWithTheirSource(t *testing.T) {
+	runTest(t, func(first *testutils.TestServerHandler) {
+		runTest(t, func(second *testutils.TestServerHandler) {
+			m, err := NewMergedEventSource([]string{first.URL, second.URL})
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer m.Close()
+
+			go first.Send("data: first\n\n")
+			go second.Send("data: second\n\n")
+
+			sources := map[string]string{}
+			for i := 0; i < 2; i++ {
+				select {
+				case ev := <-m.MessageEvents():
+					sources[ev.Data] = ev.Fields()[SourceField]
+				case <-time.After(time.Second):
+					assert.FailNow(t, "merged event source did not receive the events")
+				}
+			}
+			assert.Equal(t, map[string]string{"first": first.URL, "second": second.URL}, sources)
+		})
+	})
+}
+
+func TestMergedEventSourceSurvivesFailingSource(t *testing.T) {
+	runTest(t, func(handler *testutils.TestServerHandler) {
+		dead := httptest.NewServer(nil)
+		dead.Close()
+
+		out := new(syncBuffer)
+		m, err := NewMergedEventSource([]string{dead.URL, handler.URL}, WithLogger(log.New(out, "", 0)))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		go handler.Send("data: alive\n\n")
+		select {
+		case ev, ok := <-m.MessageEvents():
+			assert.True(t, ok)
+			assert.Equal(t, "alive", ev.Data)
+		case <-time.After(time.Second):
+			assert.FailNow(t, "merged event source did not receive the event")
+		}
+
+		m.Close()
+		_, ok := <-m.MessageEvents()
+		assert.False(t, ok)
+		assert.Contains(t, out.String(), "connection to "+dead.URL+" failed")
+	})
+}
+
+func TestMergedEventSourceDeliversHigherPriorityFirst(t *testing.T) {
+	runTest(t, func(fast *testutils.TestServerHandler) {
+		runTest(t, func(slow *testutils.TestServerHandler) {
+			m, err := NewMergedEventSource([]string{fast.URL, slow.URL})
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer m.Close()
+			m.SetSourcePriority(fast.URL, MinSourcePriority)
+			m.SetSourcePriority(slow.URL, MaxSourcePriority)
+
+			// Nothing is read until all the events are queued
+			for i := 0; i < 5; i++ {
+				fast.Send("data: fast\n\n")
+			}
+			slow.Send("data: slow\n\n")
+			waitQueueLen(t, m, 6)
+
+			expected := []string{"slow", "fast", "fast", "fast", "fast", "fast"}
+			assert.Equal(t, expected, receiveData(t, m, len(expected)))
+		})
+	})
+}
+
+func TestMergedEventSourceEqualPrioritiesTakeTurns(t *testing.T) {
+	runTest(t, func(first *testutils.TestServerHandler) {
+		runTest(t, func(second *testutils.TestServerHandler) {
+			m, err := NewMergedEventSource([]string{first.URL, second.URL})
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer m.Close()
+
+			for i := 0; i < 3; i++ {
+				first.Send("data: first\n\n")
+			}
+			for i := 0; i < 3; i++ {
+				second.Send("data: second\n\n")
+			}
+			waitQueueLen(t, m, 6)
+
+			// Either source may be queued first in a round, but every round
+			// delivers one event of each
+			data := receiveData(t, m, 6)
+			for i := 0; i < len(data); i += 2 {
+				assert.ElementsMatch(t, []string{"first", "second"}, data[i:i+2], "sources did not take turns: %v", data)
+			}
+		})
+	})
+}
+
+func TestMergedEventSourceWithOptions(t *testing.T) {
+	headers := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		headers <- req.Header.Get("X-Tenant")
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("data: event\n\n"))
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	m, err := NewMergedEventSource([]string{server.URL + "/a", server.URL + "/b"}, WithHeader("X-Tenant", "acme"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer m.Close()
+
+	assert.Equal(t, "acme", <-headers)
+	assert.Equal(t, "acme", <-headers)
+}
+
+func TestMergedEventSourceCloseWhileConnecting(t *testing.T) {
+	// The server never sends the response headers
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	m, err := NewMergedEventSource([]string{server.URL})
+	if !assert.NoError(t, err) {
+		return
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		m.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		assert.FailNow(t, "Close waited for the pending connection")
+	}
+}
+
+func TestMergedEventSourceWithoutURL(t *testing.T) {
+	_, err := NewMergedEventSource(nil)
+	assert.Equal(t, ErrNoURL, err)
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func waitQueueLen(t *testing.T, m *MergedEventSource, n int) {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		m.queueMu.Lock()
+		l := len(m.queue)
+		m.queueMu.Unlock()
+		if l == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	assert.FailNow(t, "events were not queued")
+}
+
+func receiveData(t *testing.T, m *MergedEventSource, n int) []string {
+	var data []string
+	for i := 0; i < n; i++ {
+		select {
+		case ev := <-m.MessageEvents():
+			data = append(data, ev.Data)
+		case <-time.After(time.Second):
+			assert.FailNow(t, "merged event source did not receive the events")
+		}
+	}
+	return data
+}
@@ -1,8 +1,85 @@
 package sse
 
+import (
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
 // MessageEvent presents the payload being parsed from an EventSource.
 type MessageEvent struct {
 	LastEventID string
 	Name        string
 	Data        string
 }
+
+// eventExtras holds what the package attaches to the events it creates. It is
+// kept out of MessageEvent so that the struct stays comparable and can still be
+// built from an unkeyed literal.
+type eventExtras struct {
+	fields map[string]string
+	// See meta_events.go
+	meta bool
+}
+
+// extras maps the address of an event to its eventExtras. Entries are removed
+// by a finalizer once the event is garbage collected, which happens before its
+// address can be reused.
+var extras sync.Map
+
+func eventKey(ev *MessageEvent) uintptr {
+	return uintptr(unsafe.Pointer(ev))
+}
+
+// Fields returns the raw fields attached to the event, such as the source
+// of events received from a MergedEventSource or the fields stored by a FieldParser.
+// It returns nil if there are none. Fields are attached to the event pointer:
+// copying the MessageEvent does not copy them.
+func (ev *MessageEvent) Fields() map[string]string {
+	if x := ev.extras(); x != nil {
+		return x.fields
+	}
+	return nil
+}
+
+func (ev *MessageEvent) setField(name, value string) {
+	ev.updateExtras(func(x *eventExtras) {
+		fields := make(map[string]string, len(x.fields)+1)
+		for k, v := range x.fields {
+			fields[k] = v
+		}
+		fields[name] = value
+		x.fields = fields
+	})
+}
+
+// clone returns a copy of ev, raw fields and meta-event flag included.
+func (ev *MessageEvent) clone() *MessageEvent {
+	evCopy := *ev
+	if x := ev.extras(); x != nil {
+		xCopy := *x
+		evCopy.updateExtras(func(x *eventExtras) { *x = xCopy })
+	}
+	return &evCopy
+}
+
+func (ev *MessageEvent) extras() *eventExtras {
+	if x, ok := extras.Load(eventKey(ev)); ok {
+		return x.(*eventExtras)
+	}
+	return nil
+}
+
+// updateExtras must only be called on events allocated by the package, before
+// they are handed over to the consumer.
+func (ev *MessageEvent) updateExtras(update func(x *eventExtras)) {
+	x := ev.extras()
+	if x == nil {
+		x = &eventExtras{}
+		extras.Store(eventKey(ev), x)
+		runtime.SetFinalizer(ev, func(ev *MessageEvent) {
+			extras.Delete(eventKey(ev))
+		})
+	}
+	update(x)
+}
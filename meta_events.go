@@ -0,0 +1,54 @@
+package sse
+
+import "strconv"
+
+// Names of the meta-events sent on MessageEvents, see WithMetaEvents.
+const (
+	// MetaEventOpen is sent once connected, before the events of the connection.
+	MetaEventOpen = "open"
+	// MetaEventError is sent when the connection is lost or a reconnection
+	// attempt fails, with the error message as data.
+	MetaEventError = "error"
+	// MetaEventReconnecting is sent before every reconnection attempt, with the
+	// attempt number, starting at 0, as data.
+	MetaEventReconnecting = "reconnecting"
+)
+
+// WithMetaEvents sends meta-events describing the connectivity of the event source
+// on MessageEvents, in order with the events of the stream, so that a single loop
+// can react to both. Meta-events are told apart with IsMeta.
+// Failures of the first connection, reported by NewEventSource or Connect, do not
+// send meta-events.
+func WithMetaEvents() EventSourceOption {
+	return func(es *EventSource) {
+		es.metaEvents = true
+	}
+}
+
+// IsMeta returns true if the event is a meta-event sent by the event source,
+// see WithMetaEvents, rather than an event of the stream.
+func (ev *MessageEvent) IsMeta() bool {
+	x := ev.extras()
+	return x != nil && x.meta
+}
+
+// sendMeta sends a meta-event if enabled.
+func (es *EventSource) sendMeta(name, data string) {
+	if es.metaEvents {
+		ev := &MessageEvent{LastEventID: es.LastEventID(), Name: name, Data: data}
+		ev.updateExtras(func(x *eventExtras) { x.meta = true })
+		es.send(ev)
+	}
+}
+
+func (es *EventSource) sendMetaError(err error) {
+	if es.metaEvents {
+		es.sendMeta(MetaEventError, err.Error())
+	}
+}
+
+func (es *EventSource) sendMetaReconnecting(attempt int) {
+	if es.metaEvents {
+		es.sendMeta(MetaEventReconnecting, strconv.Itoa(attempt))
+	}
+}
@@ -0,0 +1,59 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourceWithMetaEvents(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			rw.Header().Set("Content-Type", allowedContentType)
+			rw.Write([]byte("retry: 1\nid: 1\ndata: first\n\n"))
+		case 2:
+			rw.WriteHeader(http.StatusInternalServerError)
+		default:
+			rw.Header().Set("Content-Type", allowedContentType)
+			rw.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL, WithMetaEvents(), WithStatusPolicy(DefaultStatusPolicy))
+	if !assert.NoError(t, err) {
+		return
+	}
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+
+	type event struct {
+		meta           bool
+		name, data, id string
+	}
+	var events []event
+	for ev := range es.MessageEvents() {
+		events = append(events, event{ev.IsMeta(), ev.Name, ev.Data, ev.LastEventID})
+	}
+	if assert.Len(t, events, 8) {
+		assert.Contains(t, events[4].data, "unexpected status 500")
+		events[4].data = "500"
+	}
+	assert.Equal(t, []event{
+		{true, MetaEventOpen, "", ""},
+		{false, "", "first", "1"},
+		{true, MetaEventError, ErrStreamClosed.Error(), "1"},
+		{true, MetaEventReconnecting, "0", "1"},
+		{true, MetaEventError, "500", "1"},
+		{true, MetaEventReconnecting, "1", "1"},
+		{true, MetaEventOpen, "", "1"},
+		{true, MetaEventError, ErrNoContent.Error(), "1"},
+	}, events)
+}
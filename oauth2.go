@@ -0,0 +1,30 @@
+package sse
+
+import (
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+// WithOAuth2 authorizes every connection attempt with a bearer token from ts.
+// The token is only fetched before connecting: its expiry during an active
+// stream does not interrupt it, a fresh token is used on reconnection.
+// If ts fails, the connection attempt fails with an *SSEError wrapping the
+// error of ts, e.g. an *oauth2.RetrieveError.
+func WithOAuth2(ts oauth2.TokenSource) EventSourceOption {
+	return func(es *EventSource) {
+		es.tokenSource = ts
+	}
+}
+
+func (es *EventSource) authorize(req *http.Request) error {
+	if es.tokenSource == nil {
+		return nil
+	}
+	token, err := es.tokenSource.Token()
+	if err != nil {
+		return &SSEError{Code: ErrCodeNetwork, Message: "cannot get oauth2 token", Cause: err}
+	}
+	token.SetAuthHeader(req)
+	return nil
+}
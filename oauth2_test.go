@@ -0,0 +1,71 @@
+package sse
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+type tokenSourceFunc func() (*oauth2.Token, error)
+
+func (f tokenSourceFunc) Token() (*oauth2.Token, error) {
+	return f()
+}
+
+func TestEventSourceOAuth2(t *testing.T) {
+	auths := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		auths <- req.Header.Get("Authorization")
+		rw.Header().Set("Content-Type", allowedContentType)
+		// The stream ends right away, so that the event source reconnects
+		fmt.Fprint(rw, "retry: 1\n\n")
+	}))
+	defer server.Close()
+
+	var tokens int32
+	ts := tokenSourceFunc(func() (*oauth2.Token, error) {
+		n := atomic.AddInt32(&tokens, 1)
+		return &oauth2.Token{AccessToken: fmt.Sprintf("token-%d", n)}, nil
+	})
+	es, err := NewEventSource(server.URL, WithOAuth2(ts))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+
+	for _, expected := range []string{"Bearer token-1", "Bearer token-2"} {
+		select {
+		case auth := <-auths:
+			assert.Equal(t, expected, auth)
+		case <-time.After(time.Second):
+			assert.FailNow(t, "no connection attempt")
+		}
+	}
+}
+
+func TestEventSourceOAuth2Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		t.Error("unexpected request without token")
+	}))
+	defer server.Close()
+
+	ts := tokenSourceFunc(func() (*oauth2.Token, error) {
+		return nil, &oauth2.RetrieveError{Response: &http.Response{StatusCode: http.StatusUnauthorized}}
+	})
+	_, err := NewEventSource(server.URL, WithOAuth2(ts))
+	var retrieveErr *oauth2.RetrieveError
+	if assert.True(t, errors.As(err, &retrieveErr)) {
+		assert.Equal(t, http.StatusUnauthorized, retrieveErr.Response.StatusCode)
+	}
+}
@@ -0,0 +1,54 @@
+package sse
+
+// OverflowPolicy tells what an EventSource does with a received event when the
+// MessageEvents channel is full, see WithOverflowPolicy.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for the consumer to receive the event, which stops
+	// reading the stream meanwhile. It is the default policy.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest drops the oldest buffered event to make room for the
+	// received one.
+	OverflowDropOldest
+	// OverflowDropNewest drops the received event.
+	OverflowDropNewest
+)
+
+// WithOverflowPolicy sets what to do with received events when the consumer of
+// the MessageEvents channel lags behind. Dropped events are counted in
+// Stats.EventsDropped. OverflowDropOldest requires a buffer, see
+// WithEventBufferSize; without one, it behaves like OverflowDropNewest.
+func WithOverflowPolicy(policy OverflowPolicy) EventSourceOption {
+	return func(es *EventSource) {
+		es.overflow = policy
+	}
+}
+
+// sendOrDrop delivers ev without blocking, according to the overflow policy.
+// Must be called with closedMutex held.
+func (es *EventSource) sendOrDrop(ev *MessageEvent) {
+	for {
+		select {
+		case es.out <- ev:
+			return
+		default:
+		}
+		if es.overflow == OverflowDropNewest || cap(es.out) == 0 {
+			es.recordDrop()
+			return
+		}
+		select {
+		case <-es.out:
+			es.recordDrop()
+		default:
+			// Received by the consumer meanwhile
+		}
+	}
+}
+
+func (es *EventSource) recordDrop() {
+	es.stateMutex.Lock()
+	es.eventsDropped++
+	es.stateMutex.Unlock()
+}
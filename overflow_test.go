@@ -0,0 +1,47 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourceOverflowPolicies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("data: 1\n\ndata: 2\n\ndata: 3\n\ndata: 4\n\ndata: 5\n\n"))
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	for policy, expected := range map[OverflowPolicy][]string{
+		OverflowDropOldest: {"4", "5"},
+		OverflowDropNewest: {"1", "2"},
+	} {
+		es, err := NewEventSource(server.URL, WithEventBufferSize(2), WithOverflowPolicy(policy))
+		if !assert.NoError(t, err) {
+			return
+		}
+		go func() {
+			for range es.ReadyState() {
+			}
+		}()
+		// Nothing is received, yet the stream is read entirely
+		deadline := time.Now().Add(time.Second)
+		for es.Stats().EventsDropped < 3 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		assert.Equal(t, uint64(5), es.Stats().TotalEventsReceived)
+		assert.Equal(t, uint64(3), es.Stats().EventsDropped)
+
+		var data []string
+		for _, ev := range es.Drain() {
+			data = append(data, ev.Data)
+		}
+		assert.Equal(t, expected, data, "policy %d", policy)
+	}
+}
@@ -0,0 +1,83 @@
+package sse
+
+import "errors"
+
+var (
+	// ErrPaused error is the ReadyState error of an event source disconnected by Pause.
+	ErrPaused = errors.New("eventsource: paused")
+)
+
+// Pause disconnects the event source until Resume is called, e.g. while a
+// mobile application is in background. The ready state is Connecting while
+// paused, with ErrPaused as error.
+// Pausing a paused or closed event source does nothing.
+func (es *EventSource) Pause() {
+	es.pauseMutex.Lock()
+	defer es.pauseMutex.Unlock()
+	if es.resumed != nil || es.isClosed() {
+		return
+	}
+	es.resumed = make(chan struct{})
+	// A connection in progress is closed by connectOnce once established
+	es.closedMutex.RLock()
+	if es.resp != nil {
+		es.resp.Body.Close()
+	}
+	es.closedMutex.RUnlock()
+}
+
+// Resume reconnects an event source disconnected by Pause, sending the id of
+// the last event received in the Last-Event-ID header so that the stream goes
+// on where it stopped.
+// Resuming an event source which is not paused does nothing.
+func (es *EventSource) Resume() {
+	es.pauseMutex.Lock()
+	defer es.pauseMutex.Unlock()
+	if es.resumed != nil {
+		close(es.resumed)
+		es.resumed = nil
+	}
+}
+
+func (es *EventSource) isPaused() bool {
+	es.pauseMutex.Lock()
+	defer es.pauseMutex.Unlock()
+	return es.resumed != nil
+}
+
+// waitResume blocks while the event source is paused.
+// It returns false if the event source is closed meanwhile.
+func (es *EventSource) waitResume() bool {
+	es.pauseMutex.Lock()
+	resumed := es.resumed
+	es.pauseMutex.Unlock()
+	if resumed == nil {
+		return true
+	}
+	select {
+	case <-resumed:
+		return true
+	case <-es.closing:
+		return false
+	}
+}
+
+// pause waits for the event source disconnected by Pause to be resumed, then
+// reconnects right away.
+func (es *EventSource) pause() {
+	es.setReadyState(Status{Connecting, ErrPaused})
+	if !es.waitResume() {
+		return
+	}
+	es.reconnectInfo = &ReconnectInfo{Cause: ErrPaused}
+	err := es.connectOnce()
+	switch {
+	case err == nil:
+	case es.mustReconnect(err):
+		es.notifyError(err)
+		es.reconnect(err)
+	default:
+		es.notifyError(err)
+		es.Close(err)
+	}
+}
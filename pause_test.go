@@ -0,0 +1,91 @@
+package sse
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourcePauseResume(t *testing.T) {
+	lastEventIDs := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		lastEventIDs <- req.Header.Get("Last-Event-ID")
+		rw.Header().Set("Content-Type", allowedContentType)
+		if req.Header.Get("Last-Event-ID") == "" {
+			rw.Write([]byte("id: 1\ndata: first\n\n"))
+		} else {
+			rw.Write([]byte("id: 2\ndata: second\n\n"))
+		}
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+	assert.Equal(t, "", <-lastEventIDs)
+	assert.Equal(t, "first", (<-es.MessageEvents()).Data)
+	assert.Equal(t, []ReadyState{Connecting, Open}, collectStates(es.ReadyState()))
+
+	es.Pause()
+	es.Pause()
+	assert.Equal(t, Status{Connecting, ErrPaused}, <-es.ReadyState())
+	select {
+	case <-lastEventIDs:
+		assert.Fail(t, "paused event source reconnected")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	es.Resume()
+	es.Resume()
+	assert.Equal(t, "1", <-lastEventIDs)
+	assert.Equal(t, "second", (<-es.MessageEvents()).Data)
+	assert.Equal(t, []ReadyState{Connecting, Open}, collectStates(es.ReadyState()))
+}
+
+func TestEventSourcePauseWhileConnecting(t *testing.T) {
+	requests := make(chan struct{}, 2)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests <- struct{}{}
+		<-release
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("data: event\n\n"))
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+	defer close(release)
+
+	es := New(server.URL)
+	defer es.Close(nil)
+	connected := make(chan error)
+	go func() { connected <- es.Connect(context.Background()) }()
+
+	// Paused before the response headers are received
+	<-requests
+	es.Pause()
+	release <- struct{}{}
+	assert.NoError(t, <-connected)
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case status := <-es.ReadyState():
+			if status.Err == ErrPaused {
+				assert.Equal(t, Connecting, status.ReadyState)
+				assert.Len(t, requests, 0)
+				return
+			}
+		case <-timeout:
+			assert.FailNow(t, "the connection established while pausing was not closed")
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package sse
+
+import "sync"
+
+type (
+	// Pool shares a single EventSource between many workers.
+	// Every event is delivered to exactly one worker, whichever is ready first.
+	Pool struct {
+		es        *EventSource
+		done      chan struct{}
+		closeOnce sync.Once
+	}
+)
+
+// NewPool connects to url with opts and returns a Pool backed by a single EventSource.
+// Reconnections are handled by the EventSource, hence worker channels stay open
+// until the Pool is closed.
+func NewPool(url string, opts ...EventSourceOption) (*Pool, error) {
+	es, err := NewEventSource(url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Pool{es: es, done: make(chan struct{})}, nil
+}
+
+// Worker returns a new channel of events. Events are dispatched among the
+// channels of all workers. The channel is closed once the Pool is closed.
+func (p *Pool) Worker() <-chan *MessageEvent {
+	out := make(chan *MessageEvent)
+	go func() {
+		defer close(out)
+		for ev := range p.es.MessageEvents() {
+			select {
+			case out <- ev:
+			case <-p.done:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// URL returns the URL of the underlying event source.
+func (p *Pool) URL() string {
+	return p.es.URL()
+}
+
+// Close the underlying event source and all the worker channels.
+func (p *Pool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.done)
+		p.es.Close(nil)
+	})
+}
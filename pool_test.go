@@ -0,0 +1,87 @@
+package sse
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-rfc/sse/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoolDispatchesEachEventToOneWorker(t *testing.T) {
+	runTest(t, func(handler *testutils.TestServerHandler) {
+		pool, err := NewPool(handler.URL)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, handler.URL, pool.URL())
+
+		const workers, events = 4, 20
+		received := make(chan int, events)
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(worker int, ch <-chan *MessageEvent) {
+				defer wg.Done()
+				for range ch {
+					received <- worker
+				}
+			}(i, pool.Worker())
+		}
+
+		go func() {
+			for i := 0; i < events; i++ {
+				handler.Send(newMessageEventString("", "", 32))
+			}
+		}()
+		for i := 0; i < events; i++ {
+			select {
+			case <-received:
+			case <-time.After(time.Second):
+				assert.FailNow(t, "events were not dispatched to the workers")
+			}
+		}
+
+		// Once closed, worker channels are closed and no event is received twice
+		pool.Close()
+		wg.Wait()
+		assert.Len(t, received, 0)
+	})
+}
+
+func TestPoolWorkerAfterClose(t *testing.T) {
+	runTest(t, func(handler *testutils.TestServerHandler) {
+		pool, err := NewPool(handler.URL)
+		if !assert.NoError(t, err) {
+			return
+		}
+		pool.Close()
+		pool.Close()
+
+		_, ok := <-pool.Worker()
+		assert.False(t, ok)
+	})
+}
+
+func TestPoolWithOptions(t *testing.T) {
+	server, requests := newReconnectingServer(0)
+	defer server.Close()
+
+	pool, err := NewPool(server.URL, WithHeader("X-Tenant", "acme"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer pool.Close()
+	assert.Equal(t, "acme", (<-requests).Header.Get("X-Tenant"))
+}
+
+func TestPoolWithInvalidContentType(t *testing.T) {
+	runTest(t, func(handler *testutils.TestServerHandler) {
+		handler.ContentType = contentTypeTextPlain
+		pool, err := NewPool(handler.URL)
+		assert.True(t, errors.Is(err, ErrContentType))
+		assert.Nil(t, pool)
+	})
+}
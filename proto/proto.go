@@ -0,0 +1,33 @@
+/*
+
+Package proto converts between protocol buffers messages and events whose data
+holds the base64 encoded wire format of a message.
+
+*/
+package proto
+
+import (
+	"encoding/base64"
+
+	"github.com/go-rfc/sse"
+	pb "google.golang.org/protobuf/proto"
+)
+
+// UnmarshalProto decodes the base64 data of ev and unmarshals it into msg.
+func UnmarshalProto(ev *sse.MessageEvent, msg pb.Message) error {
+	b, err := base64.StdEncoding.DecodeString(ev.Data)
+	if err != nil {
+		return err
+	}
+	return pb.Unmarshal(b, msg)
+}
+
+// EventFromProto returns an event named name with the given id, whose data is the
+// base64 encoded wire format of msg.
+func EventFromProto(msg pb.Message, name, id string) (*sse.MessageEvent, error) {
+	b, err := pb.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return &sse.MessageEvent{LastEventID: id, Name: name, Data: base64.StdEncoding.EncodeToString(b)}, nil
+}
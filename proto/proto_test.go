@@ -0,0 +1,32 @@
+package proto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-rfc/sse"
+	"github.com/stretchr/testify/assert"
+	pb "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestProtoRoundTrip(t *testing.T) {
+	sent := timestamppb.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	ev, err := EventFromProto(sent, "tick", "42")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "tick", ev.Name)
+	assert.Equal(t, "42", ev.LastEventID)
+	assert.NotContains(t, ev.Data, "\n")
+
+	received := &timestamppb.Timestamp{}
+	if assert.NoError(t, UnmarshalProto(ev, received)) {
+		assert.True(t, pb.Equal(sent, received))
+	}
+}
+
+func TestUnmarshalProtoInvalidBase64(t *testing.T) {
+	err := UnmarshalProto(&sse.MessageEvent{Data: "not base64!"}, &timestamppb.Timestamp{})
+	assert.Error(t, err)
+}
@@ -1,5 +1,11 @@
 package sse
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
 //go:generate stringer -type=ReadyState
 
 // ReadyState indicates the state of the EventSource.
@@ -15,3 +21,54 @@ const (
 	// Closed after the connection is closed.
 	Closed
 )
+
+// ErrInvalidReadyState is returned when unmarshaling an unknown ReadyState.
+var ErrInvalidReadyState = errors.New("eventsource: invalid ready state")
+
+// MarshalText encodes the state as its name, e.g. "Open".
+func (s ReadyState) MarshalText() ([]byte, error) {
+	if s > Closed {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidReadyState, uint16(s))
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a state from its name.
+func (s *ReadyState) UnmarshalText(text []byte) error {
+	for state := Connecting; state <= Closed; state++ {
+		if state.String() == string(text) {
+			*s = state
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidReadyState, text)
+}
+
+// MarshalJSON encodes the state as a JSON string holding its name.
+func (s ReadyState) MarshalJSON() ([]byte, error) {
+	text, err := s.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes a state from either its name or its numeric value.
+func (s *ReadyState) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var name string
+		if err := json.Unmarshal(data, &name); err != nil {
+			return err
+		}
+		return s.UnmarshalText([]byte(name))
+	}
+	var n uint16
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	if ReadyState(n) > Closed {
+		return fmt.Errorf("%w: %d", ErrInvalidReadyState, n)
+	}
+	*s = ReadyState(n)
+	return nil
+}
@@ -0,0 +1,52 @@
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadyStateMarshalJSON(t *testing.T) {
+	out, err := json.Marshal(struct {
+		State ReadyState `json:"state"`
+	}{Open})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"state":"Open"}`, string(out))
+
+	_, err = json.Marshal(ReadyState(42))
+	assert.True(t, errors.Is(err, ErrInvalidReadyState))
+}
+
+func TestReadyStateUnmarshalJSON(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		expected ReadyState
+	}{
+		{`"Connecting"`, Connecting},
+		{`"Closing"`, Closing},
+		{`1`, Open},
+		{`3`, Closed},
+	} {
+		var state ReadyState
+		if assert.NoError(t, json.Unmarshal([]byte(test.input), &state), test.input) {
+			assert.Equal(t, test.expected, state, test.input)
+		}
+	}
+
+	for _, input := range []string{`"open"`, `4`, `-1`, `true`} {
+		var state ReadyState
+		assert.Error(t, json.Unmarshal([]byte(input), &state), input)
+	}
+}
+
+func TestReadyStateTextRoundTrip(t *testing.T) {
+	for state := Connecting; state <= Closed; state++ {
+		text, err := state.MarshalText()
+		assert.NoError(t, err)
+		var decoded ReadyState
+		assert.NoError(t, decoded.UnmarshalText(text))
+		assert.Equal(t, state, decoded)
+	}
+}
@@ -0,0 +1,46 @@
+package sse
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// WithPermanentRedirects makes the event source reconnect directly to the
+// target of permanent redirects (301 Moved Permanently and 308 Permanent
+// Redirect), which then becomes its URL. Temporary redirects (302, 303 and 307)
+// are followed again on every connection.
+// Redirects are followed according to the CheckRedirect policy of the client.
+func WithPermanentRedirects() EventSourceOption {
+	return func(es *EventSource) {
+		es.permanentRedirects = true
+	}
+}
+
+// Origin returns the origin (scheme, host and port) of the URL the event source
+// is connected to, which differs from the one of URL when a redirect was followed.
+// It is empty until the first connection.
+func (es *EventSource) Origin() string {
+	es.stateMutex.RLock()
+	defer es.stateMutex.RUnlock()
+	return es.origin
+}
+
+// trackRedirects records the origin of the final request of resp, and stores
+// its URL if it was only reached through permanent redirects.
+func (es *EventSource) trackRedirects(resp *http.Response) {
+	final := resp.Request
+	permanent := final.Response != nil
+	for req := final; req.Response != nil; req = req.Response.Request {
+		if code := req.Response.StatusCode; code != http.StatusMovedPermanently && code != http.StatusPermanentRedirect {
+			permanent = false
+		}
+	}
+
+	es.stateMutex.Lock()
+	defer es.stateMutex.Unlock()
+	es.origin = (&url.URL{Scheme: final.URL.Scheme, Host: final.URL.Host}).String()
+	if permanent && es.permanentRedirects {
+		es.logf("eventsource: %s permanently redirected to %s", es.url, final.URL)
+		es.url = final.URL.String()
+	}
+}
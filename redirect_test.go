@@ -0,0 +1,59 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourceWithPermanentRedirects(t *testing.T) {
+	var requests int32
+	target := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&requests, 1) > 1 {
+			rw.WriteHeader(http.StatusNoContent)
+			return
+		}
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("retry: 1\ndata: first\n\n"))
+	}))
+	defer target.Close()
+
+	for _, tc := range []struct {
+		name      string
+		code      int
+		opts      []EventSourceOption
+		redirects int32
+	}{
+		{"permanent", http.StatusPermanentRedirect, []EventSourceOption{WithPermanentRedirects()}, 1},
+		{"permanent not stored", http.StatusMovedPermanently, nil, 2},
+		{"temporary", http.StatusTemporaryRedirect, []EventSourceOption{WithPermanentRedirects()}, 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+			var redirects int32
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				atomic.AddInt32(&redirects, 1)
+				http.Redirect(rw, req, target.URL, tc.code)
+			}))
+			defer server.Close()
+
+			es, err := NewEventSource(server.URL, tc.opts...)
+			if !assert.NoError(t, err) {
+				return
+			}
+			go func() {
+				for range es.ReadyState() {
+				}
+			}()
+			go discardMessageEvents(es)
+			<-es.Done()
+
+			assert.Equal(t, tc.redirects, atomic.LoadInt32(&redirects))
+			assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+			assert.Equal(t, target.URL, es.Origin())
+		})
+	}
+}
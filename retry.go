@@ -0,0 +1,43 @@
+package sse
+
+import "time"
+
+// WithMinRetry sets the minimum reconnection delay: smaller retry times sent by
+// the server are raised to d, so that a misconfigured server cannot make its
+// clients reconnect in a tight loop.
+func WithMinRetry(d time.Duration) EventSourceOption {
+	return func(es *EventSource) {
+		es.minRetry = d
+	}
+}
+
+// WithMaxRetry sets the maximum reconnection delay: larger retry times sent by
+// the server are lowered to d.
+func WithMaxRetry(d time.Duration) EventSourceOption {
+	return func(es *EventSource) {
+		es.maxRetry = d
+	}
+}
+
+// Retry returns the current retry time, i.e. the delay before reconnecting
+// unless a reconnection strategy is set, once clamped by WithMinRetry and
+// WithMaxRetry. It is updated as events are received.
+func (es *EventSource) Retry() time.Duration {
+	return time.Duration(es.retry.Load())
+}
+
+// clampRetry applies the minimum and maximum retry times to d.
+func (es *EventSource) clampRetry(d time.Duration) time.Duration {
+	if es.maxRetry > 0 && d > es.maxRetry {
+		d = es.maxRetry
+	}
+	if d < es.minRetry {
+		d = es.minRetry
+	}
+	return d
+}
+
+// updateRetry stores the retry time of the decoder, see Retry.
+func (es *EventSource) updateRetry() {
+	es.retry.Store(int64(es.clampRetry(time.Duration(es.d.Retry()) * time.Millisecond)))
+}
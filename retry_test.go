@@ -0,0 +1,53 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourceRetryClamping(t *testing.T) {
+	es := New("", WithMinRetry(5*time.Second), WithMaxRetry(time.Minute))
+	delay := func() time.Duration {
+		d, _ := es.reconnectDelay(0)
+		return d
+	}
+	assert.Equal(t, 5*time.Second, es.Retry(), "default retry time must be clamped")
+
+	es.d = NewDecoder(strings.NewReader("retry: 1\n\n"))
+	es.d.Decode()
+	assert.Equal(t, 5*time.Second, delay())
+	es.d = NewDecoder(strings.NewReader("retry: 3600000\n\n"))
+	es.d.Decode()
+	assert.Equal(t, time.Minute, delay())
+
+	WithBackoff(func(int) time.Duration { return 0 })(es)
+	assert.Equal(t, time.Minute, delay(), "server retry time must be clamped")
+}
+
+func TestEventSourceRetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("retry: 10\ndata: first\n\n"))
+		rw.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL, WithMinRetry(50*time.Millisecond))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+	assert.Equal(t, defaultRetry*time.Millisecond, es.Retry())
+	<-es.MessageEvents()
+	assert.Equal(t, 50*time.Millisecond, es.Retry())
+}
@@ -1,5 +1,10 @@
 package sse
 
+import (
+	"bufio"
+	"bytes"
+)
+
 // scanLinesCRLF is a variation of bufio.ScanLines that also recognizes
 // just CR as EOL (as specified in the EventSource spec)
 func scanLinesCR(data []byte, atEOF bool) (advance int, token []byte, err error) {
@@ -35,3 +40,83 @@ func scanLinesCR(data []byte, atEOF bool) (advance int, token []byte, err error)
 	// Request more data.
 	return 0, nil, nil
 }
+
+// LineEndingMode defines which line terminators are recognized by a Decoder.
+type LineEndingMode int
+
+const (
+	// LineEndingAuto accepts CRLF, LF and CR as line terminators, as specified in the EventSource spec.
+	LineEndingAuto LineEndingMode = iota
+	// LineEndingLFOnly only accepts LF as line terminator.
+	LineEndingLFOnly
+	// LineEndingCRLFOnly only accepts CRLF as line terminator.
+	LineEndingCRLFOnly
+	// LineEndingCROnly only accepts CR as line terminator.
+	LineEndingCROnly
+)
+
+func (m LineEndingMode) splitFunc() bufio.SplitFunc {
+	switch m {
+	case LineEndingLFOnly:
+		return scanLinesSeparator([]byte{'\n'})
+	case LineEndingCRLFOnly:
+		return scanLinesSeparator([]byte{'\r', '\n'})
+	case LineEndingCROnly:
+		return scanLinesSeparator([]byte{'\r'})
+	default:
+		return scanLinesCR
+	}
+}
+
+// scanLinesSeparator returns a split function recognizing sep as the only EOL.
+// Any other terminator is kept as part of the line.
+func scanLinesSeparator(sep []byte) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		if i := bytes.Index(data, sep); i >= 0 {
+			return i + len(sep), data[:i], nil
+		}
+
+		// If we're at EOF, we have a final, non-terminated line. Return it.
+		if atEOF {
+			return len(data), data, nil
+		}
+		// Request more data.
+		return 0, nil, nil
+	}
+}
+
+// recoveringSplit wraps split so that lines longer than the scanner buffer do
+// not stop the scanner. Instead, an empty token is returned and the error is
+// reported through lineErr, then the remainder of the line is skipped.
+func (d *Decoder) recoveringSplit(split bufio.SplitFunc) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		advance, token, err = split(data, atEOF)
+		if advance > 0 || err != nil {
+			if d.discarding {
+				// End of the overlong line reached
+				d.discarding = false
+				return advance, nil, err
+			}
+			return advance, token, err
+		}
+		if token != nil || len(data) < d.maxLineSize {
+			return advance, token, err
+		}
+
+		// The buffer is full, drop its content. A trailing CR is kept as it
+		// could be followed by a LF.
+		advance = len(data)
+		if data[advance-1] == '\r' {
+			advance--
+		}
+		if d.discarding {
+			return advance, nil, nil
+		}
+		d.discarding = true
+		d.lineErr = bufio.ErrTooLong
+		return advance, data[:0], nil
+	}
+}
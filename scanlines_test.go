@@ -61,3 +61,29 @@ func TestScanLines(t *testing.T) {
 		}
 	}
 }
+
+func TestScanLinesSeparator(t *testing.T) {
+	assert := assert.New(t)
+	for _, test := range []struct {
+		mode    LineEndingMode
+		in      string
+		atEOF   bool
+		advance int
+		line    string
+	}{
+		{LineEndingLFOnly, "abc\r\n", false, 5, "abc\r"},
+		{LineEndingLFOnly, "abc\r", false, 0, ""},
+		{LineEndingLFOnly, "abc\r", true, 4, "abc\r"},
+		{LineEndingCRLFOnly, "abc\ndef\r\n", false, 9, "abc\ndef"},
+		{LineEndingCRLFOnly, "abc\r", false, 0, ""},
+		{LineEndingCROnly, "abc\r\n", false, 4, "abc"},
+		{LineEndingCROnly, "abc\n", true, 4, "abc\n"},
+	} {
+		t.Logf("mode: %d, in: %#v, atEOF: %v", test.mode, test.in, test.atEOF)
+		advance, line, err := test.mode.splitFunc()([]byte(test.in), test.atEOF)
+		if assert.NoError(err) {
+			assert.Equal(test.advance, advance)
+			assert.Equal(test.line, string(line))
+		}
+	}
+}
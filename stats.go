@@ -0,0 +1,62 @@
+package sse
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats reports the activity of an EventSource since its creation, see EventSource.Stats.
+type Stats struct {
+	// ConnectCount counts the successful connections, including reconnections.
+	ConnectCount uint64 `json:"connect_count"`
+	// DisconnectCount counts the connections that ended, cleanly or not.
+	DisconnectCount     uint64 `json:"disconnect_count"`
+	TotalBytesReceived  int64  `json:"total_bytes_received"`
+	TotalEventsReceived uint64 `json:"total_events_received"`
+	// EventsDropped counts the events dropped by the overflow policy, see WithOverflowPolicy.
+	EventsDropped uint64 `json:"events_dropped"`
+	// ConsecutiveFailures counts the failed connection attempts since the last
+	// successful one.
+	ConsecutiveFailures uint32     `json:"consecutive_failures"`
+	LastConnectedAt     time.Time  `json:"last_connected_at"`
+	CurrentReadyState   ReadyState `json:"current_ready_state"`
+	// AverageEventRatePerSec is the number of events received per second since
+	// the creation of the event source.
+	AverageEventRatePerSec float64 `json:"average_event_rate_per_sec"`
+}
+
+// Stats returns a snapshot of the statistics of the event source.
+// It is safe to call Stats concurrently.
+func (es *EventSource) Stats() Stats {
+	es.stateMutex.RLock()
+	stats := Stats{
+		ConnectCount:        es.connectCount,
+		DisconnectCount:     es.disconnectCount,
+		TotalBytesReceived:  int64(atomic.LoadUint64(&es.bytesReceived)),
+		TotalEventsReceived: es.eventsReceived,
+		EventsDropped:       es.eventsDropped,
+		ConsecutiveFailures: es.consecutiveFailures,
+		LastConnectedAt:     es.lastConnectedAt,
+		CurrentReadyState:   es.State(),
+	}
+	createdAt := es.createdAt
+	es.stateMutex.RUnlock()
+
+	if elapsed := time.Since(createdAt).Seconds(); elapsed > 0 {
+		stats.AverageEventRatePerSec = float64(stats.TotalEventsReceived) / elapsed
+	}
+	return stats
+}
+
+// recordConnectAttempt updates the statistics with the outcome of a connection attempt.
+func (es *EventSource) recordConnectAttempt(err error) {
+	es.stateMutex.Lock()
+	defer es.stateMutex.Unlock()
+	if err != nil {
+		es.consecutiveFailures++
+		return
+	}
+	es.connectCount++
+	es.consecutiveFailures = 0
+	es.lastConnectedAt = time.Now()
+}
@@ -0,0 +1,63 @@
+package sse
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourceStats(t *testing.T) {
+	const stream = "retry: 1\ndata: a\n\ndata: b\n\n"
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		rw.Header().Set("Content-Type", allowedContentType)
+		if requests > 1 {
+			// Stop reconnections
+			rw.WriteHeader(http.StatusNoContent)
+			return
+		}
+		rw.Write([]byte(stream))
+	}))
+	defer server.Close()
+
+	before := time.Now()
+	es, err := NewEventSource(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	stats := es.Stats()
+	assert.Equal(t, uint64(1), stats.ConnectCount)
+	assert.False(t, stats.LastConnectedAt.Before(before))
+
+	go discardMessageEvents(es)
+	collectStates(es.ReadyState())
+
+	stats = es.Stats()
+	assert.Equal(t, uint64(2), stats.ConnectCount)
+	assert.Equal(t, uint64(2), stats.DisconnectCount)
+	assert.Equal(t, int64(len(stream)), stats.TotalBytesReceived)
+	assert.Equal(t, uint64(2), stats.TotalEventsReceived)
+	assert.Equal(t, uint32(0), stats.ConsecutiveFailures)
+	assert.Equal(t, Closed, stats.CurrentReadyState)
+	assert.Greater(t, stats.AverageEventRatePerSec, 0.0)
+
+	out, err := json.Marshal(stats)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(out), `"current_ready_state":"Closed"`)
+	}
+}
+
+func TestEventSourceStatsCountsConsecutiveFailures(t *testing.T) {
+	es := &EventSource{stateMutex: new(sync.RWMutex)}
+	es.recordConnectAttempt(ErrContentType)
+	es.recordConnectAttempt(ErrContentType)
+	assert.Equal(t, uint32(2), es.Stats().ConsecutiveFailures)
+	es.recordConnectAttempt(nil)
+	assert.Equal(t, uint32(0), es.Stats().ConsecutiveFailures)
+}
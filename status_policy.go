@@ -0,0 +1,76 @@
+package sse
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// StatusPolicy decides whether to reconnect after a connection attempt answered
+// with an unexpected status code, and the minimum delay before the next attempt.
+type StatusPolicy func(resp *http.Response) (reconnect bool, retryAfter time.Duration)
+
+// WithStatusPolicy sets the policy deciding whether to reconnect when the server
+// answers a reconnection attempt with an unexpected status code, see
+// DefaultStatusPolicy. The decision is reported on Errors by the Reconnect and
+// RetryAfter fields of the *HTTPStatusError.
+// Without a policy, the event source only reconnects on 429 Too Many Requests
+// and 503 Service Unavailable status codes with a valid Retry-After header,
+// after the delay it specifies. A 204 No Content status always stops the event
+// source, see ErrNoContent.
+func WithStatusPolicy(policy StatusPolicy) EventSourceOption {
+	return func(es *EventSource) {
+		es.statusPolicy = policy
+	}
+}
+
+// DefaultStatusPolicy reconnects on 5xx status codes and 429 Too Many Requests,
+// waiting at least the duration of the Retry-After header of 429 and 503
+// Service Unavailable responses, if any. It stops on any other status code.
+func DefaultStatusPolicy(resp *http.Response) (bool, time.Duration) {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		return true, parseRetryAfter(resp.Header.Get("Retry-After"))
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return true, 0
+	default:
+		return false, 0
+	}
+}
+
+// parseRetryAfter parses a Retry-After header value, either a number of seconds
+// or an HTTP date. It returns 0 if the value is empty or invalid.
+func parseRetryAfter(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// newHTTPStatusError returns the error of a connection attempt answered with
+// resp, applying the status policy.
+func (es *EventSource) newHTTPStatusError(resp *http.Response, url string) *HTTPStatusError {
+	err := &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, URL: url}
+	if es.statusPolicy != nil {
+		err.Reconnect, err.RetryAfter = es.statusPolicy(resp)
+	} else if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		err.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		err.Reconnect = err.RetryAfter > 0
+	}
+	return err
+}
+
+// retryAfter returns the minimum delay before reconnecting after err.
+func retryAfter(err error) time.Duration {
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.RetryAfter
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package sse
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourceWithStatusPolicy(t *testing.T) {
+	var requests int32
+	times := make(chan time.Time, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		times <- time.Now()
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			rw.Header().Set("Content-Type", allowedContentType)
+			rw.Write([]byte("retry: 1\n\n"))
+		case 2:
+			rw.Header().Set("Retry-After", "1")
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		case 3:
+			rw.WriteHeader(http.StatusBadGateway)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	errs := make(chan error, 10)
+	es, err := NewEventSource(server.URL, WithStatusPolicy(DefaultStatusPolicy), WithErrorHandler(func(err error) { errs <- err }))
+	if !assert.NoError(t, err) {
+		return
+	}
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+	<-es.Done()
+
+	assert.Equal(t, ErrStreamClosed, <-errs)
+	for _, expected := range []HTTPStatusError{
+		{StatusCode: http.StatusServiceUnavailable, Reconnect: true, RetryAfter: time.Second},
+		{StatusCode: http.StatusBadGateway, Reconnect: true},
+		{StatusCode: http.StatusNotFound},
+	} {
+		var statusErr *HTTPStatusError
+		if assert.True(t, errors.As(<-errs, &statusErr)) {
+			assert.Equal(t, expected.StatusCode, statusErr.StatusCode)
+			assert.Equal(t, expected.Reconnect, statusErr.Reconnect)
+			assert.Equal(t, expected.RetryAfter, statusErr.RetryAfter)
+		}
+	}
+	assert.True(t, hasErrorCode(es.Err(), ErrCodeHTTPStatus))
+
+	<-times
+	unavailable, retried := <-times, <-times
+	assert.True(t, retried.Sub(unavailable) >= time.Second, "Retry-After not respected")
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, 2*time.Second, parseRetryAfter("2"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(""))
+	d := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, d > 58*time.Second && d <= time.Minute, "unexpected delay %v", d)
+}
+
+func TestEventSourceRetryAfterWithoutPolicy(t *testing.T) {
+	var requests int32
+	times := make(chan time.Time, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		times <- time.Now()
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			rw.Header().Set("Content-Type", allowedContentType)
+			rw.Write([]byte("retry: 1\n\n"))
+		case 2:
+			rw.Header().Set("Retry-After", "1")
+			rw.WriteHeader(http.StatusTooManyRequests)
+		default:
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+	<-es.Done()
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests), "503 without Retry-After must not be retried")
+	var statusErr *HTTPStatusError
+	if assert.True(t, errors.As(es.Err(), &statusErr)) {
+		assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+	}
+	<-times
+	limited, retried := <-times, <-times
+	assert.True(t, retried.Sub(limited) >= time.Second, "Retry-After not respected")
+}
@@ -0,0 +1,124 @@
+package sse
+
+import "sync"
+
+// subscription delivers a copy of the events matching name, or of every event
+// if all is set, to its own channel.
+type subscription struct {
+	name   string
+	all    bool
+	out    chan *MessageEvent
+	done   chan struct{}
+	once   sync.Once
+	mu     sync.Mutex
+	closed bool
+}
+
+// Subscribe returns a channel receiving a copy of every event named name,
+// together with a function cancelling the subscription and closing the channel.
+// The cancel function is safe to call multiple times.
+// Every call to Subscribe returns an independent channel, which is also closed
+// once the event source is closed.
+// While at least one subscription or listener (see On) exists, events are only
+// delivered to them and are not sent on MessageEvents, which then does not need
+// to be consumed; meta-events, see WithMetaEvents, are still sent on MessageEvents.
+func (es *EventSource) Subscribe(name string) (<-chan *MessageEvent, func()) {
+	return es.subscribe(&subscription{name: name})
+}
+
+// SubscribeAll is like Subscribe for every event, whatever its name, so that
+// several goroutines can each receive all the events instead of splitting them
+// by receiving from MessageEvents. Meta-events, see WithMetaEvents, are only
+// sent on MessageEvents.
+func (es *EventSource) SubscribeAll() (<-chan *MessageEvent, func()) {
+	return es.subscribe(&subscription{all: true})
+}
+
+func (es *EventSource) subscribe(sub *subscription) (<-chan *MessageEvent, func()) {
+	sub.out = make(chan *MessageEvent)
+	sub.done = make(chan struct{})
+
+	es.closedMutex.RLock()
+	defer es.closedMutex.RUnlock()
+	if es.closed {
+		sub.cancel()
+		return sub.out, func() {}
+	}
+
+	es.subsMutex.Lock()
+	es.subs[sub] = struct{}{}
+	es.subsMutex.Unlock()
+	return sub.out, func() { es.unsubscribe(sub) }
+}
+
+// Events returns a channel receiving a copy of every event named name, like
+// addEventListener in browsers. The channel is closed once the event source is
+// closed, and cannot be canceled earlier: delivery blocks until the event is
+// received, hence the channel must be consumed until then. Callers who want to
+// stop receiving events early must use Subscribe, which returns a cancel function.
+func (es *EventSource) Events(name string) <-chan *MessageEvent {
+	events, _ := es.Subscribe(name)
+	return events
+}
+
+// publish sends a copy of ev to every matching subscription.
+// It returns false if there is no subscription at all.
+func (es *EventSource) publish(ev *MessageEvent) bool {
+	es.subsMutex.RLock()
+	subscribed := len(es.subs) > 0
+	var matching []*subscription
+	for sub := range es.subs {
+		if sub.all || sub.name == ev.Name {
+			matching = append(matching, sub)
+		}
+	}
+	es.subsMutex.RUnlock()
+
+	for _, sub := range matching {
+		sub.send(ev.clone())
+	}
+	return subscribed
+}
+
+func (es *EventSource) unsubscribe(sub *subscription) {
+	es.subsMutex.Lock()
+	delete(es.subs, sub)
+	es.subsMutex.Unlock()
+	sub.cancel()
+}
+
+// Must be called with closedMutex held.
+func (es *EventSource) closeSubscriptions() {
+	es.subsMutex.Lock()
+	subs := es.subs
+	es.subs = make(map[*subscription]struct{})
+	es.subsMutex.Unlock()
+
+	for sub := range subs {
+		sub.cancel()
+	}
+}
+
+// send blocks until ev is received or the subscription is cancelled.
+func (sub *subscription) send(ev *MessageEvent) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.closed {
+		return
+	}
+	select {
+	case sub.out <- ev:
+	case <-sub.done:
+	}
+}
+
+func (sub *subscription) cancel() {
+	sub.once.Do(func() {
+		// Unblock any pending send before closing the channel
+		close(sub.done)
+		sub.mu.Lock()
+		sub.closed = true
+		close(sub.out)
+		sub.mu.Unlock()
+	})
+}
@@ -0,0 +1,149 @@
+package sse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-rfc/sse/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeReceivesMatchingEvents(t *testing.T) {
+	runTest(t, func(handler *testutils.TestServerHandler) {
+		es, err := NewEventSource(handler.URL)
+		assert.Nil(t, err)
+		go discardMessageEvents(es)
+
+		updates1, cancel1 := es.Subscribe("update")
+		updates2, cancel2 := es.Subscribe("update")
+		defer cancel2()
+		alerts, cancelAlerts := es.Subscribe("alert")
+		defer cancelAlerts()
+
+		go handler.Send("event: update\ndata: 1\n\n")
+
+		var received []*MessageEvent
+		for len(received) < 2 {
+			select {
+			case ev := <-updates1:
+				received = append(received, ev)
+			case ev := <-updates2:
+				received = append(received, ev)
+			case <-alerts:
+				assert.Fail(t, "alert subscription received an update event")
+			case <-time.After(time.Second):
+				assert.FailNow(t, "subscriptions did not receive the event")
+			}
+		}
+		assert.Equal(t, "1", received[0].Data)
+		assert.Equal(t, "update", received[1].Name)
+		assert.False(t, received[0] == received[1], "subscriptions must receive independent copies")
+
+		cancel1()
+		cancel1()
+		_, ok := <-updates1
+		assert.False(t, ok)
+
+		go handler.Send("event: alert\ndata: 2\n\n")
+		ev := <-alerts
+		assert.Equal(t, "2", ev.Data)
+
+		es.Close(nil)
+		_, ok = <-updates2
+		assert.False(t, ok)
+	})
+}
+
+func TestSubscribeAllFansOutEvents(t *testing.T) {
+	runTest(t, func(handler *testutils.TestServerHandler) {
+		es, err := NewEventSource(handler.URL)
+		assert.Nil(t, err)
+		go discardMessageEvents(es)
+
+		all1, cancel1 := es.SubscribeAll()
+		all2, cancel2 := es.SubscribeAll()
+		defer cancel2()
+
+		go handler.Send("data: 1\n\nevent: update\ndata: 2\n\n")
+		var received1, received2 []string
+		for len(received1)+len(received2) < 4 {
+			select {
+			case ev := <-all1:
+				received1 = append(received1, ev.Data)
+			case ev := <-all2:
+				received2 = append(received2, ev.Data)
+			case <-time.After(time.Second):
+				assert.FailNow(t, "subscriptions did not receive the events")
+			}
+		}
+		assert.Equal(t, []string{"1", "2"}, received1)
+		assert.Equal(t, []string{"1", "2"}, received2)
+
+		cancel1()
+		_, ok := <-all1
+		assert.False(t, ok)
+		es.Close(nil)
+		_, ok = <-all2
+		assert.False(t, ok)
+	})
+}
+
+func TestSubscribeAllWithoutReadingMessageEvents(t *testing.T) {
+	runTest(t, func(handler *testutils.TestServerHandler) {
+		es, err := NewEventSource(handler.URL)
+		assert.Nil(t, err)
+		defer es.Close(nil)
+
+		all, cancel := es.SubscribeAll()
+		defer cancel()
+		go handler.Send("data: 1\n\ndata: 2\n\n")
+		for _, expected := range []string{"1", "2"} {
+			select {
+			case ev := <-all:
+				assert.Equal(t, expected, ev.Data)
+			case <-time.After(time.Second):
+				assert.FailNow(t, "unread MessageEvents blocked the subscription")
+			}
+		}
+		assert.Len(t, es.MessageEvents(), 0)
+	})
+}
+
+func TestEventsAreClosedWithEventSource(t *testing.T) {
+	runTest(t, func(handler *testutils.TestServerHandler) {
+		es, err := NewEventSource(handler.URL)
+		assert.Nil(t, err)
+		go discardMessageEvents(es)
+
+		tickers := es.Events("ticker")
+		go handler.Send("event: other\ndata: 1\n\nevent: ticker\ndata: 2\n\n")
+		select {
+		case ev := <-tickers:
+			assert.Equal(t, &MessageEvent{Name: "ticker", Data: "2"}, ev)
+		case <-time.After(time.Second):
+			assert.FailNow(t, "ticker event not received")
+		}
+
+		es.Close(nil)
+		_, ok := <-tickers
+		assert.False(t, ok)
+	})
+}
+
+func TestSubscribeAfterClose(t *testing.T) {
+	runTest(t, func(handler *testutils.TestServerHandler) {
+		es, err := NewEventSource(handler.URL)
+		assert.Nil(t, err)
+		es.Close(nil)
+
+		events, cancel := es.Subscribe("update")
+		_, ok := <-events
+		assert.False(t, ok)
+		cancel()
+	})
+}
+
+func discardMessageEvents(es *EventSource) {
+	for range es.MessageEvents() {
+	}
+}
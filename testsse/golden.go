@@ -0,0 +1,85 @@
+/*
+
+Package testsse provides helpers to test code producing or consuming
+server-sent events.
+
+*/
+package testsse
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-rfc/sse"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+var update = flag.Bool("update", false, "update the golden files used by testsse.GoldenTest")
+
+// GoldenTest serializes the given events to the SSE wire format and compares
+// the output with the contents of testdata/<name>.golden.
+// On mismatch, the test fails with a unified diff of both wire formats.
+// Run the tests with -update to regenerate the golden files.
+func GoldenTest(t *testing.T, name string, got []*sse.MessageEvent) {
+	t.Helper()
+
+	out := new(bytes.Buffer)
+	e := sse.NewEncoder(out)
+	for _, ev := range got {
+		if _, err := e.Write(ev); err != nil {
+			t.Fatalf("testsse: cannot encode event: %v", err)
+		}
+	}
+
+	path := goldenPath(name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("testsse: cannot create testdata directory: %v", err)
+		}
+		if err := ioutil.WriteFile(path, out.Bytes(), 0644); err != nil {
+			t.Fatalf("testsse: cannot update golden file: %v", err)
+		}
+		return
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("testsse: cannot read golden file (run with -update to create it): %v", err)
+	}
+	if bytes.Equal(expected, out.Bytes()) {
+		return
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(expected)),
+		B:        difflib.SplitLines(out.String()),
+		FromFile: path,
+		ToFile:   "got",
+		Context:  3,
+	})
+	if err != nil {
+		t.Fatalf("testsse: cannot diff against golden file: %v", err)
+	}
+	t.Errorf("testsse: output does not match %s:\n%s", path, diff)
+}
+
+// GoldenDecoder opens testdata/<name>.golden and returns a Decoder reading
+// from it. The file is closed once the test completes.
+func GoldenDecoder(t *testing.T, name string) *sse.Decoder {
+	t.Helper()
+
+	f, err := os.Open(goldenPath(name))
+	if err != nil {
+		t.Fatalf("testsse: cannot open golden file: %v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return sse.NewDecoder(f)
+}
+
+func goldenPath(name string) string {
+	return filepath.Join("testdata", name+".golden")
+}
@@ -0,0 +1,31 @@
+package testsse
+
+import (
+	"io"
+	"testing"
+
+	"github.com/go-rfc/sse"
+	"github.com/stretchr/testify/assert"
+)
+
+var stockEvents = []*sse.MessageEvent{
+	{LastEventID: "1", Data: "AAPL 30.09"},
+	{LastEventID: "2", Data: "GOOG 1450.16"},
+}
+
+func TestGoldenTest(t *testing.T) {
+	GoldenTest(t, "stocks", stockEvents)
+}
+
+func TestGoldenDecoder(t *testing.T) {
+	d := GoldenDecoder(t, "stocks")
+	for _, expected := range stockEvents {
+		ev, err := d.Decode()
+		if assert.NoError(t, err) {
+			assert.Equal(t, expected.LastEventID, ev.LastEventID)
+			assert.Equal(t, expected.Data, ev.Data)
+		}
+	}
+	_, err := d.Decode()
+	assert.Equal(t, io.EOF, err)
+}
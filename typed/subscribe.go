@@ -0,0 +1,48 @@
+package typed
+
+import (
+	"sync"
+
+	"github.com/go-rfc/sse"
+)
+
+// Subscribe subscribes to the events named name on es and sends their data
+// unmarshaled with unmarshal on the returned channel. Events failing to unmarshal
+// are logged with the logger of es, see sse.WithLogger, and skipped.
+// The returned function cancels the subscription and closes the channel, it is
+// safe to call multiple times. The channel is also closed once es is closed.
+// See sse.EventSource.Subscribe.
+func Subscribe[T any](es *sse.EventSource, name string, unmarshal func([]byte) (T, error)) (<-chan T, func()) {
+	events, unsubscribe := es.Subscribe(name)
+	out := make(chan T)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		defer close(out)
+		for ev := range events {
+			v, err := unmarshal([]byte(ev.Data))
+			if err != nil {
+				if logger := es.Logger(); logger != nil {
+					logger.Printf("typed: cannot unmarshal %q event from %s: %v connection_id=%s", name, es.URL(), err, es.ID())
+				}
+				continue
+			}
+			select {
+			case out <- v:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return out, func() {
+		once.Do(func() {
+			close(done)
+			unsubscribe()
+			<-finished
+		})
+	}
+}
@@ -0,0 +1,90 @@
+/*
+
+Package typed provides event sources decoding the data of their events into
+Go values.
+
+*/
+package typed
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-rfc/sse"
+)
+
+type (
+	// TypedEventSource wraps an EventSource and unmarshals the JSON data of
+	// every message event into a T.
+	// Its MessageEvents channel is consumed internally and must not be used.
+	TypedEventSource[T any] struct {
+		*sse.EventSource
+		events chan T
+		errs   chan UnmarshalError[T]
+	}
+
+	// UnmarshalError reports an event whose data could not be unmarshaled into a T.
+	UnmarshalError[T any] struct {
+		Event *sse.MessageEvent
+		Err   error
+	}
+)
+
+// NewTypedEventSource connects to url and returns a TypedEventSource.
+// Reconnections are handled by the underlying EventSource.
+func NewTypedEventSource[T any](url string, opts ...sse.EventSourceOption) (*TypedEventSource[T], error) {
+	es, err := sse.NewEventSource(url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	t := &TypedEventSource[T]{
+		EventSource: es,
+		events:      make(chan T),
+		errs:        make(chan UnmarshalError[T], 16),
+	}
+	go t.consume()
+	return t, nil
+}
+
+// TypedEvents returns a channel of the unmarshaled events.
+// It is closed once the event source is closed.
+func (t *TypedEventSource[T]) TypedEvents() <-chan T {
+	return t.events
+}
+
+// UnmarshalErrors returns a channel of the events that could not be unmarshaled.
+// Like sse.EventSource.Errors, it is buffered and errors are dropped when it is
+// full, so that an unread channel does not block TypedEvents.
+func (t *TypedEventSource[T]) UnmarshalErrors() <-chan UnmarshalError[T] {
+	return t.errs
+}
+
+func (t *TypedEventSource[T]) consume() {
+	defer close(t.errs)
+	defer close(t.events)
+	for ev := range t.EventSource.MessageEvents() {
+		var v T
+		if err := json.Unmarshal([]byte(ev.Data), &v); err != nil {
+			select {
+			case t.errs <- UnmarshalError[T]{Event: ev, Err: err}:
+			default:
+			}
+			continue
+		}
+		select {
+		case t.events <- v:
+		case <-t.EventSource.Done():
+			return
+		}
+	}
+}
+
+func (e UnmarshalError[T]) Error() string {
+	var v T
+	return fmt.Sprintf("typed: cannot unmarshal event data into %T: %v", v, e.Err)
+}
+
+// Unwrap returns the unmarshaling error.
+func (e UnmarshalError[T]) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,121 @@
+package typed
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/go-rfc/sse"
+	"github.com/go-rfc/sse/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+type quote struct {
+	Symbol string  `json:"symbol"`
+	Price  float64 `json:"price"`
+}
+
+func TestTypedEventSource(t *testing.T) {
+	handler := testutils.NewDefaultTestServerHandler(t)
+	defer handler.Close()
+	handler.MaxRequestsToProcess = 2
+
+	es, err := NewTypedEventSource[quote](handler.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+
+	go handler.Send("retry: 1\ndata: {\"symbol\": \"AAPL\", \"price\": 30.09}\n\n")
+	assert.Equal(t, quote{"AAPL", 30.09}, <-es.TypedEvents())
+
+	go handler.Send("data: not json\n\n")
+	unmarshalErr := <-es.UnmarshalErrors()
+	assert.Equal(t, "not json", unmarshalErr.Event.Data)
+	assert.Error(t, unmarshalErr.Unwrap())
+	assert.Contains(t, unmarshalErr.Error(), "typed.quote")
+
+	// Reconnections are transparent
+	handler.CloseActiveRequest()
+	go handler.Send("data: {\"symbol\": \"GOOG\", \"price\": 1450.16}\n\n")
+	assert.Equal(t, quote{"GOOG", 1450.16}, <-es.TypedEvents())
+
+	es.Close(nil)
+	_, ok := <-es.TypedEvents()
+	assert.False(t, ok)
+}
+
+func TestTypedEventSourceWithoutReadingErrors(t *testing.T) {
+	handler := testutils.NewDefaultTestServerHandler(t)
+	defer handler.Close()
+
+	es, err := NewTypedEventSource[quote](handler.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+
+	go func() {
+		for i := 0; i < 32; i++ {
+			handler.Send("data: not json\n\n")
+		}
+		handler.Send("data: {\"symbol\": \"AAPL\", \"price\": 30.09}\n\n")
+	}()
+	select {
+	case q := <-es.TypedEvents():
+		assert.Equal(t, quote{"AAPL", 30.09}, q)
+	case <-time.After(time.Second):
+		assert.FailNow(t, "unread unmarshal errors blocked the typed events")
+	}
+}
+
+func TestTypedEventSourceCloseWithoutReading(t *testing.T) {
+	handler := testutils.NewDefaultTestServerHandler(t)
+	defer handler.Close()
+
+	es, err := NewTypedEventSource[quote](handler.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	handler.Send("data: {\"symbol\": \"AAPL\", \"price\": 30.09}\n\n")
+	time.Sleep(50 * time.Millisecond)
+
+	// The pending event is dropped rather than blocking forever
+	es.Close(nil)
+	time.Sleep(50 * time.Millisecond)
+	_, ok := <-es.TypedEvents()
+	assert.False(t, ok)
+}
+
+func TestSubscribe(t *testing.T) {
+	handler := testutils.NewDefaultTestServerHandler(t)
+	defer handler.Close()
+
+	logs := new(bytes.Buffer)
+	es, err := sse.NewEventSource(handler.URL, sse.WithLogger(log.New(logs, "", 0)))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+	go func() {
+		for range es.MessageEvents() {
+		}
+	}()
+
+	quotes, cancel := Subscribe(es, "quote", func(data []byte) (q quote, err error) {
+		err = json.Unmarshal(data, &q)
+		return
+	})
+
+	go handler.Send("event: quote\ndata: not json\n\nevent: other\ndata: {}\n\nevent: quote\ndata: {\"symbol\": \"AAPL\", \"price\": 30.09}\n\n")
+	assert.Equal(t, quote{"AAPL", 30.09}, <-quotes)
+
+	cancel()
+	cancel()
+	_, ok := <-quotes
+	assert.False(t, ok)
+	assert.Contains(t, logs.String(), `typed: cannot unmarshal "quote" event from `+handler.URL)
+}
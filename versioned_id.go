@@ -0,0 +1,65 @@
+package sse
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidVersionedID error indicates an event id does not follow the
+	// v<version>:<unix timestamp>:<nonce> convention.
+	ErrInvalidVersionedID = errors.New("sse: invalid versioned id")
+	// ErrUnexpectedIDVersion error indicates a versioned event id has a different
+	// version than the one given to WithVersionedIDs.
+	ErrUnexpectedIDVersion = errors.New("decoder: unexpected id version")
+)
+
+// ParseVersionedID parses an event id following the v<version>:<unix timestamp>:<nonce>
+// convention, e.g. "v2:1704067200:abc123". The nonce may contain colons.
+func ParseVersionedID(id string) (version int, timestamp int64, nonce string, err error) {
+	parts := strings.SplitN(id, ":", 3)
+	if len(parts) != 3 || !strings.HasPrefix(parts[0], "v") {
+		return 0, 0, "", fmt.Errorf("%w: %q", ErrInvalidVersionedID, id)
+	}
+	version, err = strconv.Atoi(parts[0][1:])
+	if err != nil || version < 0 {
+		return 0, 0, "", fmt.Errorf("%w: bad version in %q", ErrInvalidVersionedID, id)
+	}
+	timestamp, err = strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("%w: bad timestamp in %q", ErrInvalidVersionedID, id)
+	}
+	return version, timestamp, parts[2], nil
+}
+
+// FormatVersionedID formats an event id following the convention parsed by ParseVersionedID.
+func FormatVersionedID(version int, timestamp int64, nonce string) string {
+	return "v" + strconv.Itoa(version) + ":" + strconv.FormatInt(timestamp, 10) + ":" + nonce
+}
+
+// WithVersionedIDs makes a Decoder in strict mode reject the events whose id is not a
+// versioned id of the given version, see ParseVersionedID. Empty ids, which reset the
+// last event id, are accepted.
+// Without strict mode, ids are not checked.
+func WithVersionedIDs(version int) DecoderOption {
+	return func(d *Decoder) {
+		d.idVersion = &version
+	}
+}
+
+// checkIDVersion validates an id field against the version given to WithVersionedIDs.
+func (d *Decoder) checkIDVersion(id string) error {
+	if d.idVersion == nil || !d.strict || id == "" {
+		return nil
+	}
+	version, _, _, err := ParseVersionedID(id)
+	if err != nil {
+		return err
+	}
+	if version != *d.idVersion {
+		return fmt.Errorf("%w: got %d, want %d", ErrUnexpectedIDVersion, version, *d.idVersion)
+	}
+	return nil
+}
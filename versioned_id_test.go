@@ -0,0 +1,70 @@
+package sse
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVersionedID(t *testing.T) {
+	version, timestamp, nonce, err := ParseVersionedID("v2:1704067200:abc:123")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, version)
+		assert.Equal(t, int64(1704067200), timestamp)
+		assert.Equal(t, "abc:123", nonce)
+	}
+	assert.Equal(t, "v2:1704067200:abc:123", FormatVersionedID(version, timestamp, nonce))
+
+	for _, id := range []string{"", "42", "2:1704067200:abc", "v:1704067200:abc", "v-1:1:abc", "v2:now:abc", "v2:1704067200"} {
+		_, _, _, err := ParseVersionedID(id)
+		assert.True(t, errors.Is(err, ErrInvalidVersionedID), id)
+	}
+}
+
+func TestDecoderWithVersionedIDs(t *testing.T) {
+	in := "id: v2:1:a\ndata: 1\n\nid: v1:2:b\ndata: 2\n\nid: 3\ndata: 3\n\n"
+
+	// Without strict mode, ids are not checked
+	decoder := NewDecoderWithOptions(bytes.NewReader([]byte(in)), WithVersionedIDs(2))
+	for _, id := range []string{"v2:1:a", "v1:2:b", "3"} {
+		ev, err := decoder.Decode()
+		if assert.NoError(t, err) {
+			assert.Equal(t, id, ev.LastEventID)
+		}
+	}
+
+	var errs []error
+	decoder = NewDecoderWithOptions(bytes.NewReader([]byte(in)), WithVersionedIDs(2), WithStrictMode(),
+		WithErrorRecovery(func(err error) { errs = append(errs, err) }))
+	ev, err := decoder.Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1", ev.Data)
+	}
+	_, err = decoder.Decode()
+	assert.Equal(t, io.EOF, err)
+	if assert.Len(t, errs, 2) {
+		assert.True(t, errors.Is(errs[0], ErrUnexpectedIDVersion))
+		assert.True(t, errors.Is(errs[1], ErrInvalidVersionedID))
+		var parseErr *ParseError
+		assert.True(t, errors.As(errs[1], &parseErr))
+		assert.Equal(t, "id", parseErr.FieldName)
+	}
+
+	// Without recovery, the rest of a rejected event is skipped
+	decoder = NewDecoderWithOptions(bytes.NewReader([]byte("id: v2:1:a\ndata: 1\n\nid: v1:2:b\ndata: rejected\n\ndata: next\n\n")),
+		WithVersionedIDs(2), WithStrictMode())
+	ev, err = decoder.Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1", ev.Data)
+	}
+	_, err = decoder.Decode()
+	assert.True(t, errors.Is(err, ErrUnexpectedIDVersion))
+	ev, err = decoder.Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "next", ev.Data)
+		assert.Equal(t, "v2:1:a", ev.LastEventID)
+	}
+}
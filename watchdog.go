@@ -0,0 +1,68 @@
+package sse
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrWatchdogTimeout error indicates no data was received from the stream
+	// within the duration given to WithWatchdog.
+	ErrWatchdogTimeout = errors.New("eventsource: no data received before the watchdog timeout")
+)
+
+// WithWatchdog reconnects to the stream when no byte at all is received within d,
+// which may happen when the server hangs or a proxy buffers the response.
+// Events, comments and partial lines all count, hence servers with quiet streams
+// are expected to send keep-alive comments more often than d, see LastContact.
+// Time spent waiting for the consumer to read MessageEvents does not count.
+// The ErrWatchdogTimeout error is reported on Errors when the watchdog fires.
+func WithWatchdog(d time.Duration) EventSourceOption {
+	return func(es *EventSource) {
+		es.watchdog = d
+	}
+}
+
+// LastContact returns the last time data was received from the stream, be it
+// events or keep-alive comments such as ": ping", or the zero time if none was.
+// See WithWatchdog to reconnect when the server stays silent for too long.
+func (es *EventSource) LastContact() time.Time {
+	if last := es.lastContact.Load(); last != 0 {
+		return time.Unix(0, last)
+	}
+	return time.Time{}
+}
+
+// watchdogReader closes rc once a read from it did not return within d.
+// The timer only runs while waiting for data, rather than between events, so
+// that large events and keep-alive comments also count as activity, and a slow
+// consumer blocking the dispatch of events does not make it fire.
+type watchdogReader struct {
+	rc    io.ReadCloser
+	d     time.Duration
+	timer *time.Timer
+	fired int32
+}
+
+func newWatchdogReader(rc io.ReadCloser, d time.Duration) *watchdogReader {
+	w := &watchdogReader{rc: rc, d: d}
+	w.timer = time.AfterFunc(d, func() {
+		atomic.StoreInt32(&w.fired, 1)
+		rc.Close()
+	})
+	// Armed by Read
+	w.timer.Stop()
+	return w
+}
+
+func (w *watchdogReader) Read(p []byte) (int, error) {
+	w.timer.Reset(w.d)
+	n, err := w.rc.Read(p)
+	w.timer.Stop()
+	if err != nil && atomic.LoadInt32(&w.fired) == 1 {
+		return n, ErrWatchdogTimeout
+	}
+	return n, err
+}
@@ -0,0 +1,145 @@
+package sse
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourceWatchdogReconnectsFrozenStream(t *testing.T) {
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt32(&connections, 1)
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("retry: 1\n\n"))
+		rw.(http.Flusher).Flush()
+		if n > 1 {
+			rw.Write([]byte("data: reconnected\n\n"))
+			rw.(http.Flusher).Flush()
+		}
+		// Hang until the client gives up
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL, WithWatchdog(50*time.Millisecond))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+
+	select {
+	case ev := <-es.MessageEvents():
+		assert.Equal(t, "reconnected", ev.Data)
+	case <-time.After(time.Second):
+		assert.FailNow(t, "the watchdog did not reconnect the stream")
+	}
+	assert.True(t, errors.Is(<-es.Errors(), ErrWatchdogTimeout))
+}
+
+func TestEventSourceWatchdogWithSlowConsumer(t *testing.T) {
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&connections, 1)
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.Write([]byte("data: first\n\ndata: second\n\n"))
+		rw.(http.Flusher).Flush()
+		for {
+			select {
+			case <-time.After(20 * time.Millisecond):
+				rw.Write([]byte(": ping\n"))
+				rw.(http.Flusher).Flush()
+			case <-req.Context().Done():
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL, WithWatchdog(200*time.Millisecond))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+
+	assert.Equal(t, "first", (<-es.MessageEvents()).Data)
+	// The consumer lags behind while the server keeps the stream alive
+	time.Sleep(600 * time.Millisecond)
+	assert.Equal(t, "second", (<-es.MessageEvents()).Data)
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, es.Errors(), 0)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&connections))
+}
+
+func TestWatchdogReaderResetsOnRead(t *testing.T) {
+	r, w := io.Pipe()
+	reader := newWatchdogReader(r, 50*time.Millisecond)
+	go func() {
+		// Keep writing more often than the watchdog timeout
+		for i := 0; i < 5; i++ {
+			time.Sleep(20 * time.Millisecond)
+			w.Write([]byte(":\n"))
+		}
+		w.Close()
+	}()
+
+	buf := make([]byte, 16)
+	for {
+		_, err := reader.Read(buf)
+		if err != nil {
+			assert.Equal(t, io.EOF, err)
+			return
+		}
+	}
+}
+
+func TestEventSourceLastContact(t *testing.T) {
+	ping := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", allowedContentType)
+		rw.(http.Flusher).Flush()
+		for {
+			select {
+			case <-ping:
+				rw.Write([]byte(": ping\n"))
+				rw.(http.Flusher).Flush()
+			case <-req.Context().Done():
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	es, err := NewEventSource(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+	assert.True(t, es.LastContact().IsZero())
+
+	for i := 0; i < 2; i++ {
+		before := time.Now()
+		ping <- struct{}{}
+		assert.Eventually(t, func() bool {
+			return !es.LastContact().Before(before)
+		}, time.Second, 5*time.Millisecond, "keep-alive comment not tracked")
+	}
+}
@@ -0,0 +1,102 @@
+package sse
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+// WithWebSocketFallback connects to wsURL with a WebSocket when the stream cannot be
+// consumed over HTTP, because the response has an unexpected status or content type,
+// as happens behind some proxies. The text messages received are parsed as the SSE
+// wire format, events may span several messages.
+// protocol is the WebSocket subprotocol requested, if not empty.
+// Once the fallback is used, reconnections go straight to the WebSocket.
+func WithWebSocketFallback(wsURL string, protocol string) EventSourceOption {
+	return func(es *EventSource) {
+		es.wsURL = wsURL
+		es.wsProtocol = protocol
+	}
+}
+
+// mustFallBack tells whether the error of an HTTP connection attempt calls for the WebSocket fallback.
+func (es *EventSource) mustFallBack(err error) bool {
+	return es.wsURL != "" && (hasErrorCode(err, ErrCodeContentType) || hasErrorCode(err, ErrCodeHTTPStatus))
+}
+
+// doWebSocketConnect returns a response whose body streams the WebSocket messages,
+// so that the rest of the event source does not depend on the transport.
+func (es *EventSource) doWebSocketConnect() (*http.Response, error) {
+	dialer := websocket.Dialer{Proxy: http.ProxyFromEnvironment}
+	if es.wsProtocol != "" {
+		dialer.Subprotocols = []string{es.wsProtocol}
+	}
+	// The handshake request is prepared like the HTTP ones, only its URL and
+	// headers are used to dial
+	req, err := http.NewRequestWithContext(es.ctx, http.MethodGet, es.wsURL, nil)
+	if err != nil {
+		return nil, &SSEError{Code: ErrCodeNetwork, Message: "cannot create request", Cause: err}
+	}
+	if es.lastEventID != "" && !es.omitIDHeader {
+		req.Header.Set("Last-Event-ID", es.lastEventID)
+	}
+	if err = es.prepareRequest(req, true); err != nil {
+		return nil, err
+	}
+	conn, resp, err := dialer.DialContext(es.ctx, req.URL.String(), req.Header)
+	if err != nil {
+		if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+			return nil, &SSEError{
+				Code:       ErrCodeHTTPStatus,
+				StatusCode: resp.StatusCode,
+				Cause:      &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, URL: es.wsURL},
+			}
+		}
+		return nil, &SSEError{Code: ErrCodeNetwork, Message: "cannot connect", Cause: err}
+	}
+	es.wsActive = true
+	return &http.Response{
+		Status:     resp.Status,
+		StatusCode: http.StatusOK,
+		Header:     resp.Header,
+		Body:       &webSocketReader{conn: conn},
+	}, nil
+}
+
+// webSocketReader reads the text messages of a WebSocket as a single stream.
+type webSocketReader struct {
+	conn *websocket.Conn
+	r    io.Reader
+}
+
+func (w *webSocketReader) Read(p []byte) (int, error) {
+	for {
+		if w.r == nil {
+			messageType, r, err := w.conn.NextReader()
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return 0, io.EOF
+			} else if err != nil {
+				return 0, err
+			}
+			if messageType != websocket.TextMessage {
+				continue
+			}
+			w.r = r
+		}
+		n, err := w.r.Read(p)
+		if err == io.EOF {
+			// End of the message, continue with the next one
+			w.r = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (w *webSocketReader) Close() error {
+	return w.conn.Close()
+}
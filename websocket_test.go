@@ -0,0 +1,58 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourceWebSocketFallback(t *testing.T) {
+	upgrader := websocket.Upgrader{Subprotocols: []string{"sse"}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", func(rw http.ResponseWriter, req *http.Request) {
+		// A proxy mangling the stream
+		rw.Header().Set("Content-Type", contentTypeTextPlain)
+	})
+	mux.HandleFunc("/ws", func(rw http.ResponseWriter, req *http.Request) {
+		// The handshake carries the headers of the options
+		assert.Equal(t, "acme", req.Header.Get("X-Tenant"))
+		conn, err := upgrader.Upgrade(rw, req, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		assert.Equal(t, "sse", conn.Subprotocol())
+		for _, message := range []string{"id: 1\ndata: first\n\n", "data: split", "\n\n"} {
+			conn.WriteMessage(websocket.TextMessage, []byte(message))
+		}
+		conn.ReadMessage() // Blocks until the client closes
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	es, err := NewEventSource(server.URL+"/events", WithWebSocketFallback(wsURL, "sse"), WithHeader("X-Tenant", "acme"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer es.Close(nil)
+	go func() {
+		for range es.ReadyState() {
+		}
+	}()
+
+	for _, expected := range []*MessageEvent{{LastEventID: "1", Data: "first"}, {LastEventID: "1", Data: "split"}} {
+		select {
+		case ev := <-es.MessageEvents():
+			assert.Equal(t, expected, ev)
+		case <-time.After(time.Second):
+			assert.FailNow(t, "event not received over the WebSocket")
+		}
+	}
+	assert.Equal(t, Open, es.State())
+}
@@ -0,0 +1,45 @@
+/*
+
+Package zstd decodes server-sent events streams compressed with Zstandard
+(Content-Encoding: zstd).
+
+*/
+package zstd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/go-rfc/sse"
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	// ErrNotZstd error indicates the input does not start with the Zstandard magic bytes.
+	ErrNotZstd = errors.New("zstd: input is not a Zstandard stream")
+
+	magic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// NewZstdDecoder returns a Decoder reading events from the Zstandard compressed input r.
+// It blocks until the first bytes of r are read, and fails with ErrNotZstd if they are
+// not the Zstandard magic bytes.
+func NewZstdDecoder(r io.Reader, opts ...sse.DecoderOption) (*sse.Decoder, error) {
+	in := bufio.NewReader(r)
+	header, err := in.Peek(len(magic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if !bytes.Equal(header, magic) {
+		return nil, ErrNotZstd
+	}
+	// Without concurrency, decompression runs in the calling goroutine: nothing
+	// needs to be released once the stream ends.
+	zr, err := zstd.NewReader(in, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return sse.NewDecoderWithOptions(zr, opts...), nil
+}
@@ -0,0 +1,45 @@
+package zstd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/go-rfc/sse"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZstdDecoderRoundTrip(t *testing.T) {
+	compressed := new(bytes.Buffer)
+	w, _ := zstd.NewWriter(compressed)
+	encoder := sse.NewEncoder(w)
+	for i := 0; i < 1000; i++ {
+		encoder.Write(&sse.MessageEvent{LastEventID: strconv.Itoa(i), Data: fmt.Sprintf(`{"seq": %d}`, i)})
+	}
+	w.Close()
+
+	decoder, err := NewZstdDecoder(compressed)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for i := 0; i < 1000; i++ {
+		ev, err := decoder.Decode()
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, &sse.MessageEvent{LastEventID: strconv.Itoa(i), Data: fmt.Sprintf(`{"seq": %d}`, i)}, ev)
+	}
+	_, err = decoder.Decode()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestZstdDecoderRejectsUncompressedInput(t *testing.T) {
+	_, err := NewZstdDecoder(bytes.NewReader([]byte("data: plain\n\n")))
+	assert.Equal(t, ErrNotZstd, err)
+
+	_, err = NewZstdDecoder(bytes.NewReader(nil))
+	assert.Equal(t, ErrNotZstd, err)
+}